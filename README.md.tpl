@@ -15,12 +15,25 @@ Supported:
 * Nominal attributes
 * Date attributes (ISO-8601 UTC only)
 * Weighted data
+* Header comments
+* Sparse format
 * Unicode
 
 Not-supported:
 
 * Relational attributes
-* Sparse format
+
+### Command-line tool
+
+```shell
+go install github.com/bsm/arff/cmd/arff@latest
+
+arff info data.arff                 # relation name, attributes and row count
+arff head -n 5 data.arff            # first rows
+arff validate *.arff                # report line-numbered errors
+arff filter "temp > 75" data.arff   # rows matching an expression
+arff convert data.arff data.csv     # convert between arff, csv and jsonl
+```
 
 ### Example: Reader
 
@@ -47,3 +60,15 @@ import (
 
 func main() {{ "ExampleWriter" | code }}
 ```
+
+### Example: Iterators
+
+```go
+import (
+  "fmt"
+
+  "github.com/bsm/arff"
+)
+
+func main() {{ "ExampleReader_All" | code }}
+```
@@ -1,6 +1,7 @@
 package arff
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"time"
@@ -16,6 +17,21 @@ const (
 	DataTypeNominal
 )
 
+// String returns the ARFF keyword of the data type
+func (t DataType) String() string {
+	switch t {
+	case DataTypeNumeric:
+		return "NUMERIC"
+	case DataTypeString:
+		return "STRING"
+	case DataTypeDate:
+		return "DATE"
+	case DataTypeNominal:
+		return "NOMINAL"
+	}
+	return "DataType(" + strconv.Itoa(int(t)) + ")"
+}
+
 // Relation contains meta-data and attribute definition
 type Relation struct {
 	// The relation name
@@ -23,15 +39,28 @@ type Relation struct {
 
 	// The attributes
 	Attributes []Attribute
+
+	// Comments preceding the relation declaration, one per line. When
+	// reading, inline comments of the declaration and comments preceding
+	// the data section are appended.
+	Comments []string
+
+	// Class is the name of the class attribute. ARFF has no notion of
+	// classes, so the last attribute is assumed when blank. Explicit
+	// classes are persisted in a header comment, e.g. "% @class 'play'",
+	// see ReaderOptions and WriterOptions.
+	Class string
 }
 
+// classCommentPrefix is the default prefix of the header comment which
+// stores the class attribute of a relation
+const classCommentPrefix = "@class "
+
 // AddAttribute stores an attribute, avoiding duplicates.
 // Include nominalVals for nominal data-types
 func (r *Relation) AddAttribute(name string, dataType DataType, nominalVals []string) error {
-	for _, attr := range r.Attributes {
-		if attr.Name == name {
-			return errAttrRedefined
-		}
+	if r.AttributeIndex(name) > -1 {
+		return errAttrRedefined
 	}
 
 	r.Attributes = append(r.Attributes, Attribute{
@@ -42,10 +71,50 @@ func (r *Relation) AddAttribute(name string, dataType DataType, nominalVals []st
 	return nil
 }
 
+// AttributeIndex returns the index of the named attribute or -1
+func (r *Relation) AttributeIndex(name string) int {
+	for i, attr := range r.Attributes {
+		if attr.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ClassIndex returns the index of the class attribute or -1, if the
+// relation has no attributes or the class attribute is unknown
+func (r *Relation) ClassIndex() int {
+	if r.Class == "" {
+		return len(r.Attributes) - 1
+	}
+	return r.AttributeIndex(r.Class)
+}
+
+// className returns the name of the class attribute, resolving the
+// implicit class, or an empty string if there is none
+func (r *Relation) className() string {
+	if pos := r.ClassIndex(); pos > -1 {
+		return r.Attributes[pos].Name
+	}
+	return ""
+}
+
+// SetClassIndex designates the attribute at index i as the class attribute
+func (r *Relation) SetClassIndex(i int) error {
+	if i < 0 || i >= len(r.Attributes) {
+		return errUnknownAttr
+	}
+	r.Class = r.Attributes[i].Name
+	return nil
+}
+
 func (r *Relation) validate() error {
 	if r.Name == "" {
 		return errMissingRelName
 	}
+	if r.Class != "" && r.AttributeIndex(r.Class) < 0 {
+		return fmt.Errorf("%s '%s'", errUnknownAttr.Error(), r.Class)
+	}
 	for _, attr := range r.Attributes {
 		if err := attr.validate(); err != nil {
 			return err
@@ -64,6 +133,10 @@ type Attribute struct {
 
 	// NominalValues are only populated for nominal types
 	NominalValues []string
+
+	// Comments preceding the attribute declaration, one per line. When
+	// reading, an inline comment of the declaration is appended.
+	Comments []string
 }
 
 func (a *Attribute) validate() error {
@@ -95,12 +168,94 @@ func (a *Attribute) parse(s string) (interface{}, error) {
 	return unquote(s), nil
 }
 
+// zero returns the value of the attribute omitted in sparse rows
+func (a *Attribute) zero() interface{} {
+	switch a.DataType {
+	case DataTypeNumeric:
+		return 0.0
+	case DataTypeDate:
+		return time.Unix(0, 0).In(utc)
+	case DataTypeNominal:
+		if len(a.NominalValues) != 0 {
+			return a.NominalValues[0]
+		}
+	}
+	return ""
+}
+
+// isZero returns true if v is the value of the attribute omitted in
+// sparse rows, see zero
+func (a *Attribute) isZero(v interface{}) bool {
+	switch a.DataType {
+	case DataTypeNumeric:
+		f, ok := numericValue(v)
+		return ok && f == 0
+	case DataTypeDate:
+		t, ok := v.(time.Time)
+		return ok && t.Equal(time.Unix(0, 0))
+	case DataTypeNominal:
+		if len(a.NominalValues) != 0 {
+			return v == a.NominalValues[0]
+		}
+	}
+	return v == ""
+}
+
+// numericValue converts numeric row values to float64
+func numericValue(v interface{}) (float64, bool) {
+	switch vv := v.(type) {
+	case float64:
+		return vv, true
+	case float32:
+		return float64(vv), true
+	case int:
+		return float64(vv), true
+	case int8:
+		return float64(vv), true
+	case int16:
+		return float64(vv), true
+	case int32:
+		return float64(vv), true
+	case int64:
+		return float64(vv), true
+	case uint:
+		return float64(vv), true
+	case uint8:
+		return float64(vv), true
+	case uint16:
+		return float64(vv), true
+	case uint32:
+		return float64(vv), true
+	case uint64:
+		return float64(vv), true
+	}
+	return 0, false
+}
+
 // DataRow represents a parsed data row
 type DataRow struct {
 	Values []interface{}
 	Weight float64
 }
 
+// weight returns the effective row weight, unweighted rows count as 1
+func (r *DataRow) weight() float64 {
+	if r.Weight == 0 {
+		return 1
+	}
+	return r.Weight
+}
+
+// Iterator iterates over data rows, it is implemented by Reader
+type Iterator interface {
+	// Next returns true if can advance the row cursor
+	Next() bool
+	// Row returns the current DataRow
+	Row() *DataRow
+	// Err returns an error if any
+	Err() error
+}
+
 // --------------------------------------------------------------------
 
 const iso8691DateFormat = "2006-01-02T15:04:05"
@@ -130,4 +285,59 @@ const (
 	errAttrMismatch    constError = "attribute mismatch"
 	errMissingRelName  constError = "missing relation name"
 	errInvalidWeight   constError = "invalid weight definition"
+	errUnknownAttr     constError = "unknown attribute"
+	errNoRelations     constError = "no relations"
+	errInvalidJoinKeys constError = "invalid join keys"
+	errNonNumericAttr  constError = "non-numeric attribute"
+	errUnfittedAttr    constError = "unfitted attribute"
+	errNonNominalAttr  constError = "non-nominal attribute"
+	errNonStringAttr   constError = "non-string attribute"
+	errBadExpr         constError = "bad expression"
+	errMissingExpr     constError = "missing expression"
+	errClassAttr       constError = "cannot transform class attribute"
 )
+
+// contextCheckInterval is the number of rows between context checks
+const contextCheckInterval = 256
+
+// contextCheck checks a context periodically, the first error is sticky
+type contextCheck struct {
+	n   int
+	err error
+}
+
+// Err returns the context error, checking ctx every contextCheckInterval
+// calls
+func (c *contextCheck) Err(ctx context.Context) error {
+	if c.err == nil && c.n%contextCheckInterval == 0 {
+		c.err = ctx.Err()
+	}
+	c.n++
+	return c.err
+}
+
+// Reset schedules a check on the next call, unless a previous check failed
+func (c *contextCheck) Reset() {
+	c.n = 0
+}
+
+// contextIterator stops iterating once ctx is done
+type contextIterator struct {
+	Iterator
+	ctx   context.Context
+	check contextCheck
+}
+
+func (it *contextIterator) Next() bool {
+	if it.check.Err(it.ctx) != nil {
+		return false
+	}
+	return it.Iterator.Next()
+}
+
+func (it *contextIterator) Err() error {
+	if err := it.check.err; err != nil {
+		return err
+	}
+	return it.Iterator.Err()
+}
@@ -16,6 +16,23 @@ var _ = Describe("Relation", func() {
 		Expect(rel.AddAttribute("foo", DataTypeDate, nil)).To(Equal(errAttrRedefined))
 	})
 
+	It("should designate class attributes", func() {
+		rel := new(Relation)
+		Expect(rel.ClassIndex()).To(Equal(-1))
+
+		Expect(rel.AddAttribute("foo", DataTypeNumeric, nil)).To(Succeed())
+		Expect(rel.AddAttribute("bar", DataTypeString, nil)).To(Succeed())
+		Expect(rel.ClassIndex()).To(Equal(1))
+
+		Expect(rel.SetClassIndex(0)).To(Succeed())
+		Expect(rel.Class).To(Equal("foo"))
+		Expect(rel.ClassIndex()).To(Equal(0))
+		Expect(rel.SetClassIndex(2)).To(Equal(errUnknownAttr))
+
+		rel.Class = "baz"
+		Expect(rel.ClassIndex()).To(Equal(-1))
+	})
+
 })
 
 func TestSuite(t *testing.T) {
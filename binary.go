@@ -0,0 +1,189 @@
+package arff
+
+import "fmt"
+
+// NominalToBinary is a Filter which expands nominal attributes into one
+// numeric 0/1 attribute per declared label, named by joining attribute
+// name and label, e.g. "outlook=sunny". Missing values are encoded as
+// missing in all expanded attributes. Encoded rows are mostly zero, see
+// Writer.SetSparse for a compact output format.
+type NominalToBinary struct {
+	// Attributes are the names of the attributes to encode, which must
+	// not include the class attribute.
+	// Default: all nominal attributes except the class attribute
+	Attributes []string
+
+	// DropFirst omits the attribute of the first label, which is then
+	// implied if all other attributes are zero
+	DropFirst bool
+
+	// Separator joins attribute names and labels. Default: "="
+	Separator string
+
+	in        *Relation
+	encodings []binaryEncoding
+	origins   map[string]binaryOrigin
+}
+
+// Init implements Filter
+func (f *NominalToBinary) Init(in *Relation) (*Relation, error) {
+	encode, err := f.attributes(in)
+	if err != nil {
+		return nil, err
+	}
+
+	sep := f.Separator
+	if sep == "" {
+		sep = "="
+	}
+
+	out := &Relation{Name: in.Name, Comments: in.Comments, Class: in.className()}
+	f.in, f.encodings, f.origins = in, f.encodings[:0], make(map[string]binaryOrigin)
+	for pos, attr := range in.Attributes {
+		if !encode[pos] {
+			if err := out.AddAttribute(attr.Name, attr.DataType, attr.NominalValues); err != nil {
+				return nil, fmt.Errorf("%s '%s'", err.Error(), attr.Name)
+			}
+			out.Attributes[len(out.Attributes)-1].Comments = attr.Comments
+			continue
+		}
+
+		enc := binaryEncoding{pos: pos, offset: len(out.Attributes), labels: make(map[string]int)}
+		for n, label := range attr.NominalValues {
+			enc.labels[label] = n
+			if n == 0 && f.DropFirst {
+				continue
+			}
+
+			name := attr.Name + sep + label
+			if err := out.AddAttribute(name, DataTypeNumeric, nil); err != nil {
+				return nil, fmt.Errorf("%s '%s'", err.Error(), name)
+			}
+			f.origins[name] = binaryOrigin{Attribute: attr.Name, Label: label}
+		}
+		enc.width = len(out.Attributes) - enc.offset
+		f.encodings = append(f.encodings, enc)
+	}
+	return out, nil
+}
+
+// Apply implements Filter
+func (f *NominalToBinary) Apply(row *DataRow) (*DataRow, error) {
+	values := make([]interface{}, 0, len(row.Values)+len(f.origins))
+
+	last := 0
+	for _, enc := range f.encodings {
+		values = append(values, row.Values[last:enc.pos]...)
+		last = enc.pos + 1
+
+		v := row.Values[enc.pos]
+		if v == nil {
+			for i := 0; i < enc.width; i++ {
+				values = append(values, nil)
+			}
+			continue
+		}
+
+		n, ok := enc.labels[fmt.Sprint(v)]
+		if !ok {
+			return nil, fmt.Errorf("value '%v' is not a declared label", v)
+		}
+		if f.DropFirst {
+			n--
+		}
+		for i := 0; i < enc.width; i++ {
+			if i == n {
+				values = append(values, 1.0)
+			} else {
+				values = append(values, 0.0)
+			}
+		}
+	}
+	values = append(values, row.Values[last:]...)
+
+	return &DataRow{Values: values, Weight: row.Weight}, nil
+}
+
+// Origin returns the source attribute and label of an encoded attribute
+func (f *NominalToBinary) Origin(name string) (attr, label string, ok bool) {
+	o, ok := f.origins[name]
+	return o.Attribute, o.Label, ok
+}
+
+// Decode reverses the encoding of a row produced by Apply
+func (f *NominalToBinary) Decode(row *DataRow) (*DataRow, error) {
+	values := make([]interface{}, 0, len(f.in.Attributes))
+
+	last := 0
+	for _, enc := range f.encodings {
+		values = append(values, row.Values[last:enc.offset]...)
+		last = enc.offset + enc.width
+
+		labels := f.in.Attributes[enc.pos].NominalValues
+		if f.DropFirst {
+			labels = labels[1:]
+		}
+
+		var value interface{}
+		missing := false
+		for i, v := range row.Values[enc.offset:last] {
+			if v == nil {
+				missing = true
+				continue
+			}
+			if x, ok := numericValue(v); !ok {
+				return nil, fmt.Errorf("value '%v' is not numeric", v)
+			} else if x != 0 {
+				value = labels[i]
+			}
+		}
+		if value == nil && !missing && f.DropFirst {
+			value = f.in.Attributes[enc.pos].NominalValues[0]
+		}
+		values = append(values, value)
+	}
+	values = append(values, row.Values[last:]...)
+
+	return &DataRow{Values: values, Weight: row.Weight}, nil
+}
+
+func (f *NominalToBinary) attributes(rel *Relation) (map[int]bool, error) {
+	encode := make(map[int]bool)
+	if len(f.Attributes) == 0 {
+		classPos := rel.ClassIndex()
+		for pos, attr := range rel.Attributes {
+			if attr.DataType == DataTypeNominal && pos != classPos {
+				encode[pos] = true
+			}
+		}
+		return encode, nil
+	}
+
+	for _, name := range f.Attributes {
+		pos := rel.AttributeIndex(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+		if rel.Attributes[pos].DataType != DataTypeNominal {
+			return nil, fmt.Errorf("%s '%s'", errNonNominalAttr.Error(), name)
+		}
+		if pos == rel.ClassIndex() {
+			return nil, fmt.Errorf("%s '%s'", errClassAttr.Error(), name)
+		}
+		encode[pos] = true
+	}
+	return encode, nil
+}
+
+// --------------------------------------------------------------------
+
+type binaryEncoding struct {
+	pos    int            // position in input rows
+	offset int            // position of the first expanded attribute
+	width  int            // number of expanded attributes
+	labels map[string]int // label indices
+}
+
+type binaryOrigin struct {
+	Attribute, Label string
+}
@@ -0,0 +1,115 @@
+package arff
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NominalToBinary", func() {
+	var data *Dataset
+
+	BeforeEach(func() {
+		var err error
+		data, err = OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		data.Rows[1].Values[0] = nil
+	})
+
+	It("should encode nominal attributes", func() {
+		f := new(NominalToBinary)
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		for _, attr := range out.Attributes {
+			names = append(names, attr.Name)
+		}
+		Expect(names).To(Equal([]string{
+			"outlook=sunny", "outlook=overcast", "outlook=rainy",
+			"temperature", "humidity",
+			"windy=TRUE", "windy=FALSE",
+			"play",
+		}))
+		Expect(out.Attributes[0].DataType).To(Equal(DataTypeNumeric))
+		Expect(out.Attributes[7]).To(Equal(data.Attributes[4]))
+		Expect(out.Class).To(Equal("play"))
+
+		Expect(out.Rows[0].Values).To(Equal([]interface{}{1.0, 0.0, 0.0, 85.0, 85.0, 0.0, 1.0, "no"}))
+		Expect(out.Rows[1].Values).To(Equal([]interface{}{nil, nil, nil, 80.0, 90.0, 1.0, 0.0, "no"}))
+
+		attr, label, ok := f.Origin("windy=TRUE")
+		Expect(ok).To(BeTrue())
+		Expect(attr).To(Equal("windy"))
+		Expect(label).To(Equal("TRUE"))
+
+		_, _, ok = f.Origin("temperature")
+		Expect(ok).To(BeFalse())
+
+		for i := range out.Rows {
+			Expect(f.Decode(&out.Rows[i])).To(Equal(&data.Rows[i]))
+		}
+	})
+
+	It("should drop first levels", func() {
+		f := &NominalToBinary{Attributes: []string{"outlook", "windy"}, DropFirst: true, Separator: "_"}
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes).To(HaveLen(6))
+		Expect(out.Attributes[0].Name).To(Equal("outlook_overcast"))
+		Expect(out.Attributes[1].Name).To(Equal("outlook_rainy"))
+		Expect(out.Attributes[4].Name).To(Equal("windy_FALSE"))
+		Expect(out.Class).To(Equal("play"))
+
+		Expect(out.Rows[0].Values).To(Equal([]interface{}{0.0, 0.0, 85.0, 85.0, 1.0, "no"}))
+		Expect(out.Rows[2].Values).To(Equal([]interface{}{1.0, 0.0, 83.0, 86.0, 1.0, "yes"}))
+
+		for i := range out.Rows {
+			Expect(f.Decode(&out.Rows[i])).To(Equal(&data.Rows[i]))
+		}
+	})
+
+	It("should validate", func() {
+		_, err := data.Filter(&NominalToBinary{Attributes: []string{"temperature"}})
+		Expect(err).To(MatchError("non-nominal attribute 'temperature'"))
+
+		_, err = data.Filter(&NominalToBinary{Attributes: []string{"play"}})
+		Expect(err).To(MatchError("cannot transform class attribute 'play'"))
+
+		data.Class = "windy"
+		_, err = data.Filter(&NominalToBinary{Attributes: []string{"outlook", "windy"}})
+		Expect(err).To(MatchError("cannot transform class attribute 'windy'"))
+
+		data.Rows[3].Values[0] = "cloudy"
+		_, err = data.Filter(new(NominalToBinary))
+		Expect(err).To(MatchError("value 'cloudy' is not a declared label"))
+
+		data.Attributes[1].Name = "outlook=sunny"
+		_, err = data.Filter(new(NominalToBinary))
+		Expect(err).To(MatchError("redefined attribute 'outlook=sunny'"))
+	})
+
+	It("should write sparse output", func() {
+		r, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		rel, it, err := FilterRows(&r.Relation, r, new(NominalToBinary))
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		w, err := NewWriter(buf, rel)
+		Expect(err).NotTo(HaveOccurred())
+		w.SetSparse(true)
+		Expect(w.AppendAll(it)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("@DATA\n{0 1,3 85,4 85,6 1,7 no}\n"))
+
+		out, err := readDataset(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows).To(HaveLen(14))
+		Expect(out.Rows[2].Values).To(Equal([]interface{}{0.0, 1.0, 0.0, 83.0, 86.0, 0.0, 1.0, "yes"}))
+	})
+
+})
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bsm/arff"
+)
+
+func init() {
+	commands = append(commands,
+		&command{
+			Name:  "info",
+			Args:  "FILE...",
+			Short: "print relation name, attributes and row count",
+			Run:   runInfo,
+		},
+		&command{
+			Name:  "head",
+			Args:  "[-n ROWS] FILE",
+			Short: "print the first rows",
+			Run:   runHead,
+		},
+		&command{
+			Name:  "tail",
+			Args:  "[-n ROWS] FILE",
+			Short: "print the last rows",
+			Run:   runTail,
+		},
+		&command{
+			Name:  "validate",
+			Args:  "FILE...",
+			Short: "check files for errors",
+			Run:   runValidate,
+		},
+		&command{
+			Name:  "cat",
+			Args:  "FILE...",
+			Short: "print files in normalised formatting",
+			Run:   runCat,
+		},
+		&command{
+			Name:  "filter",
+			Args:  "EXPR FILE",
+			Short: "print rows matching an expression, e.g. \"temperature > 75 and windy = 'TRUE'\"",
+			Run:   runFilter,
+		},
+		&command{
+			Name:  "convert",
+			Args:  "[-from FORMAT] [-to FORMAT] [-name RELATION] [-class ATTRIBUTE] SRC DST",
+			Short: "convert between arff, csv and jsonl",
+			Run:   runConvert,
+		},
+	)
+}
+
+// withReader opens name and calls fn with an ARFF reader
+func (c *cli) withReader(name string, fn func(*arff.Reader) error) error {
+	src, err := c.open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	r, err := arff.NewReader(src)
+	if err != nil {
+		return err
+	}
+	return fn(r)
+}
+
+func runInfo(c *cli, args []string) error {
+	args, err := c.parse(c.flags(), args, 1, -1)
+	if err != nil {
+		return err
+	}
+
+	for i, name := range args {
+		if i != 0 {
+			fmt.Fprintln(c.Stdout)
+		}
+		if err := c.withReader(name, func(r *arff.Reader) error {
+			rows, err := r.Count()
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(c.Stdout, 0, 4, 2, ' ', 0)
+			if len(args) > 1 {
+				fmt.Fprintf(tw, "file:\t%s\n", name)
+			}
+			fmt.Fprintf(tw, "relation:\t%s\n", r.Name)
+			if i := r.ClassIndex(); i > -1 {
+				fmt.Fprintf(tw, "class:\t%s\n", r.Attributes[i].Name)
+			}
+			fmt.Fprintf(tw, "rows:\t%d\n", rows)
+			fmt.Fprintf(tw, "attributes:\t%d\n", len(r.Attributes))
+			for _, attr := range r.Attributes {
+				fmt.Fprintf(tw, "  %s\t%s\n", attr.Name, formatDataType(&attr))
+			}
+			return tw.Flush()
+		}); err != nil {
+			return fmt.Errorf("%s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+func runHead(c *cli, args []string) error {
+	fs := c.flags()
+	n := fs.Int("n", 10, "number of rows")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	return c.withReader(args[0], func(r *arff.Reader) error {
+		w, err := arff.NewWriterSize(c.Stdout, &r.Relation, arff.DefaultBufferSize)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < *n && r.Next(); i++ {
+			if err := w.Append(r.Row()); err != nil {
+				return err
+			}
+		}
+		if err := r.Err(); err != nil {
+			return err
+		}
+		return w.Close()
+	})
+}
+
+func runTail(c *cli, args []string) error {
+	fs := c.flags()
+	num := fs.Int("n", 10, "number of rows")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	n := *num
+	if n < 0 {
+		n = 0
+	}
+
+	return c.withReader(args[0], func(r *arff.Reader) error {
+		ring := make([]*arff.DataRow, n)
+		seen := 0
+		for r.Next() {
+			if n != 0 {
+				ring[seen%n] = r.Row()
+			}
+			seen++
+		}
+		if err := r.Err(); err != nil {
+			return err
+		}
+
+		w, err := arff.NewWriterSize(c.Stdout, &r.Relation, arff.DefaultBufferSize)
+		if err != nil {
+			return err
+		}
+		for i := seen - n; i < seen; i++ {
+			if i < 0 {
+				continue
+			}
+			if err := w.Append(ring[i%n]); err != nil {
+				return err
+			}
+		}
+		return w.Close()
+	})
+}
+
+func runValidate(c *cli, args []string) error {
+	args, err := c.parse(c.flags(), args, 1, -1)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, name := range args {
+		if err := c.withReader(name, func(r *arff.Reader) error {
+			errs, err := r.Validate()
+			for _, e := range errs {
+				fmt.Fprintf(c.Stderr, "%s: %s\n", name, e.Error())
+				failed = true
+			}
+			return err
+		}); err != nil {
+			fmt.Fprintf(c.Stderr, "%s: %s\n", name, err.Error())
+			failed = true
+		}
+	}
+	if failed {
+		return errSilent
+	}
+	return nil
+}
+
+func runCat(c *cli, args []string) error {
+	args, err := c.parse(c.flags(), args, 1, -1)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range args {
+		if err := c.withReader(name, func(r *arff.Reader) error {
+			w, err := arff.NewWriterSize(c.Stdout, &r.Relation, arff.DefaultBufferSize)
+			if err != nil {
+				return err
+			}
+			if err := w.AppendAll(r); err != nil {
+				return err
+			}
+			return w.Close()
+		}); err != nil {
+			return fmt.Errorf("%s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+func runFilter(c *cli, args []string) error {
+	args, err := c.parse(c.flags(), args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	return c.withReader(args[1], func(r *arff.Reader) error {
+		return arff.ApplyFilters(c.Stdout, r, &arff.SelectRows{Expr: args[0]})
+	})
+}
+
+func runConvert(c *cli, args []string) error {
+	fs := c.flags()
+	fromFormat := fs.String("from", "", "source format, detected from file extension by default")
+	toFormat := fs.String("to", "", "destination format, detected from file extension by default")
+	name := fs.String("name", "", "relation name for csv and jsonl sources, defaults to the file name")
+	class := fs.String("class", "", "class attribute, defaults to the last attribute")
+	args, err := c.parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	srcName, dstName := args[0], args[1]
+
+	from, err := detectFormat(*fromFormat, srcName)
+	if err != nil {
+		return err
+	}
+	to, err := detectFormat(*toFormat, dstName)
+	if err != nil {
+		return err
+	}
+
+	relName := *name
+	if relName == "" {
+		relName = strings.TrimSuffix(filepath.Base(srcName), filepath.Ext(srcName))
+		if relName == "-" {
+			relName = "stdin"
+		}
+	}
+
+	src, err := c.open(srcName)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	var rel *arff.Relation
+	var rows arff.Iterator
+
+	switch from {
+	case "arff":
+		r, err := arff.NewReader(src)
+		if err != nil {
+			return err
+		}
+		rel, rows = &r.Relation, r
+	case "csv":
+		data, err := arff.ReadCSV(src, relName)
+		if err != nil {
+			return err
+		}
+		rel, rows = &data.Relation, data.Iterator()
+	case "jsonl":
+		data, err := arff.ReadJSONL(src, relName)
+		if err != nil {
+			return err
+		}
+		rel, rows = &data.Relation, data.Iterator()
+	}
+
+	if *class != "" {
+		rel.Class = *class
+	}
+
+	if to == "arff" {
+		w, err := c.createARFF(dstName, rel)
+		if err != nil {
+			return err
+		}
+		if err := w.AppendAll(rows); err != nil {
+			_ = w.Abort()
+			return err
+		}
+		return w.Close()
+	}
+
+	dst, err := c.create(dstName)
+	if err != nil {
+		return err
+	}
+
+	if to == "csv" {
+		err = arff.WriteCSV(dst, rel, rows)
+	} else {
+		err = arff.WriteJSONL(dst, rel, rows)
+	}
+	if err != nil {
+		_ = dst.Abort()
+		return err
+	}
+	return dst.Close()
+}
+
+// --------------------------------------------------------------------
+
+// detectFormat returns the format of a named file, standard I/O defaults
+// to arff
+func detectFormat(format, name string) (string, error) {
+	if format == "" && name == "-" {
+		format = "arff"
+	} else if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	}
+
+	switch format {
+	case "arff", "csv", "jsonl":
+		return format, nil
+	case "json", "ndjson":
+		return "jsonl", nil
+	case "":
+		return "", fmt.Errorf("unable to detect format of %q", name)
+	}
+	return "", fmt.Errorf("unsupported format %q", format)
+}
+
+func formatDataType(attr *arff.Attribute) string {
+	if attr.DataType != arff.DataTypeNominal {
+		return attr.DataType.String()
+	}
+	return "{" + strings.Join(attr.NominalValues, ",") + "}"
+}
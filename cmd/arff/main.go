@@ -0,0 +1,157 @@
+// Command arff performs everyday chores on ARFF files.
+//
+// Usage:
+//
+//	arff <command> [flags] [arguments]
+//
+// Run 'arff help' for a list of commands. A file name of '-' reads from
+// standard input or writes to standard output.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bsm/arff"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// command is a sub-command of the CLI
+type command struct {
+	Name  string
+	Args  string
+	Short string
+	Run   func(c *cli, args []string) error
+}
+
+var commands []*command
+
+var (
+	// errSilent signals a failure which has already been reported
+	errSilent = errors.New("silent failure")
+	// errUsage signals invalid command line arguments
+	errUsage = errors.New("invalid usage")
+)
+
+// cli holds the I/O streams of a command invocation
+type cli struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	cmd *command
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{Stdin: stdin, Stdout: stdout, Stderr: stderr}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		c.usage()
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.Name != args[0] {
+			continue
+		}
+
+		c.cmd = cmd
+		if err := cmd.Run(c, args[1:]); err == flag.ErrHelp {
+			return 0
+		} else if err == errUsage {
+			return 2
+		} else if err == errSilent {
+			return 1
+		} else if err != nil {
+			fmt.Fprintf(stderr, "arff %s: %s\n", cmd.Name, err.Error())
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "arff: unknown command %q\n", args[0])
+	c.usage()
+	return 2
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.Stderr, "usage: arff <command> [flags] [arguments]")
+	fmt.Fprintln(c.Stderr)
+	fmt.Fprintln(c.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(c.Stderr, "  %-10s %s\n", cmd.Name, cmd.Short)
+	}
+}
+
+// flags returns a flag set for the current command
+func (c *cli) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(c.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.Stderr, "usage: arff %s %s\n", c.cmd.Name, c.cmd.Args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses args and checks the number of positional arguments,
+// a max of -1 is unlimited
+func (c *cli) parse(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return nil, err
+	} else if err != nil {
+		return nil, errUsage
+	}
+
+	args = fs.Args()
+	if len(args) < min || (max > -1 && len(args) > max) {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return args, nil
+}
+
+// open opens a named input, '-' is standard input
+func (c *cli) open(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(c.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// create creates a named output, '-' is standard output. Files are
+// renamed into place on Close, see arff.CreateAtomicFile.
+func (c *cli) create(name string) (output, error) {
+	if name == "-" {
+		return nopOutput{Writer: c.Stdout}, nil
+	}
+	return arff.CreateAtomicFile(name)
+}
+
+// createARFF creates a named ARFF output, '-' is standard output
+func (c *cli) createARFF(name string, rel *arff.Relation) (*arff.Writer, error) {
+	if name == "-" {
+		return arff.NewWriterSize(c.Stdout, rel, arff.DefaultBufferSize)
+	}
+	return arff.CreateAtomic(name, rel)
+}
+
+// output is written and closed on success or aborted on failure
+type output interface {
+	io.WriteCloser
+	Abort() error
+}
+
+type nopOutput struct{ io.Writer }
+
+func (nopOutput) Close() error { return nil }
+func (nopOutput) Abort() error { return nil }
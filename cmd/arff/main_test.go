@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("arff", func() {
+	var stdout, stderr *bytes.Buffer
+
+	BeforeEach(func() {
+		stdout, stderr = new(bytes.Buffer), new(bytes.Buffer)
+	})
+
+	exec := func(stdin string, args ...string) int {
+		return run(args, strings.NewReader(stdin), stdout, stderr)
+	}
+
+	It("should print usage", func() {
+		Expect(exec("")).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring("validate"))
+
+		Expect(exec("", "unknown")).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring(`unknown command "unknown"`))
+
+		Expect(exec("", "head")).To(Equal(2))
+		Expect(stderr.String()).To(ContainSubstring("usage: arff head [-n ROWS] FILE"))
+	})
+
+	It("should print info", func() {
+		Expect(exec("", "info", "../../testdata/weather.arff")).To(Equal(0))
+		Expect(stdout.String()).To(Equal(`relation:      weather
+class:         play
+rows:          14
+attributes:    5
+  outlook      {sunny,overcast,rainy}
+  temperature  NUMERIC
+  humidity     NUMERIC
+  windy        {TRUE,FALSE}
+  play         {yes,no}
+`))
+	})
+
+	It("should print head and tail", func() {
+		Expect(exec("", "head", "-n", "2", "../../testdata/weather.arff")).To(Equal(0))
+		Expect(stdout.String()).To(HaveSuffix("@DATA\nsunny,85,85,FALSE,no\nsunny,80,90,TRUE,no\n"))
+
+		stdout.Reset()
+		Expect(exec("", "tail", "-n", "2", "../../testdata/weather.arff")).To(Equal(0))
+		Expect(stdout.String()).To(HaveSuffix("@DATA\novercast,81,75,FALSE,yes\nrainy,71,91,TRUE,no\n"))
+	})
+
+	It("should validate", func() {
+		Expect(exec("", "validate", "../../testdata/weather.arff", "../../testdata/iris.arff")).To(Equal(0))
+		Expect(stderr.String()).To(BeEmpty())
+
+		Expect(exec("@relation x\n@attribute a numeric\n@data\n1\nx\n", "validate", "-")).To(Equal(1))
+		Expect(stderr.String()).To(Equal("-: LINE 5: value 'x' is not numeric\n"))
+
+		stderr.Reset()
+		Expect(exec("@relation x\n@attribute a numeric\n@attribute b {p,q}\n@data\n1,z\nx,p\n2,q\n{1 r}\n", "validate", "-")).To(Equal(1))
+		Expect(stderr.String()).To(Equal("-: LINE 5: value 'z' is not a label of 'b'\n-: LINE 6: value 'x' is not numeric\n-: LINE 8: value 'r' is not a label of 'b'\n"))
+	})
+
+	It("should cat", func() {
+		Expect(exec("", "cat", "../../testdata/messy.arff")).To(Equal(0))
+
+		bin, err := ioutil.ReadFile("../../testdata/messy.arff")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal(string(bin)))
+	})
+
+	It("should filter", func() {
+		Expect(exec("", "filter", "outlook = 'sunny' and temperature > 75 and humidity is not missing", "../../testdata/weather.arff")).To(Equal(0))
+		Expect(stdout.String()).To(HaveSuffix("@DATA\nsunny,85,85,FALSE,no\nsunny,80,90,TRUE,no\n"))
+
+		Expect(exec("", "filter", "outlook > 5", "../../testdata/weather.arff")).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring("bad expression at 9: cannot compare string with numeric"))
+	})
+
+	It("should convert", func() {
+		dir, err := ioutil.TempDir("", "arff-cmd")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		csv := filepath.Join(dir, "weather.csv")
+		Expect(exec("", "convert", "../../testdata/weather.arff", csv)).To(Equal(0))
+		Expect(ioutil.ReadFile(csv)).To(HavePrefix("outlook,temperature,humidity,windy,play\nsunny,85,85,FALSE,no\n"))
+
+		Expect(exec("", "convert", csv, "-")).To(Equal(0))
+		Expect(stdout.String()).To(HavePrefix("@RELATION weather\n\n@ATTRIBUTE outlook {sunny,overcast,rainy}\n@ATTRIBUTE temperature NUMERIC\n"))
+
+		stdout.Reset()
+		Expect(exec("a,b\n1,x\n", "convert", "-from", "csv", "-to", "jsonl", "-", "-")).To(Equal(0))
+		Expect(stdout.String()).To(Equal(`{"a":1,"b":"x"}` + "\n"))
+
+		stdout.Reset()
+		Expect(exec("a,b\n1,x\n", "convert", "-from", "csv", "-class", "a", "-", "-")).To(Equal(0))
+		Expect(stdout.String()).To(HavePrefix("% @class a\n@RELATION stdin\n"))
+
+		Expect(exec("", "convert", "in.xls", "-")).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring(`unsupported format "xls"`))
+	})
+
+	It("should keep the mode of replaced files", func() {
+		dir, err := ioutil.TempDir("", "arff-cmd")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		for _, name := range []string{"out.csv", "out.jsonl", "out.arff"} {
+			fname := filepath.Join(dir, name)
+			Expect(ioutil.WriteFile(fname, nil, 0600)).To(Succeed())
+			Expect(os.Chmod(fname, 0640)).To(Succeed())
+			Expect(exec("", "convert", "../../testdata/weather.arff", fname)).To(Equal(0))
+
+			info, err := os.Stat(fname)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0640)), name)
+		}
+	})
+
+	It("should not leave partial files behind", func() {
+		dir, err := ioutil.TempDir("", "arff-cmd")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		const broken = "@relation x\n@attribute a numeric\n@data\n1\nx\n"
+		for _, name := range []string{"out.csv", "out.arff"} {
+			Expect(exec(broken, "convert", "-from", "arff", "-", filepath.Join(dir, name))).To(Equal(1))
+			Expect(stderr.String()).To(ContainSubstring("LINE 5: value 'x' is not numeric"))
+		}
+		Expect(ioutil.ReadDir(dir)).To(BeEmpty())
+	})
+
+})
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "arff/cmd/arff")
+}
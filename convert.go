@@ -0,0 +1,334 @@
+package arff
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteCSV writes rows as CSV to dst. The first line contains the attribute
+// names, missing values are written as empty fields and weights are omitted.
+func WriteCSV(dst io.Writer, rel *Relation, it Iterator) error {
+	w := csv.NewWriter(dst)
+
+	record := make([]string, len(rel.Attributes))
+	for i, attr := range rel.Attributes {
+		record[i] = attr.Name
+	}
+	if err := w.Write(record); err != nil {
+		return err
+	}
+
+	for it.Next() {
+		row := it.Row()
+		if len(row.Values) != len(record) {
+			return errAttrMismatch
+		}
+		for i, v := range row.Values {
+			s, err := formatValue(v)
+			if err != nil {
+				return err
+			}
+			record[i] = s
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteCSVContext writes rows as CSV to dst, like WriteCSV, but stops
+// once ctx is done
+func WriteCSVContext(ctx context.Context, dst io.Writer, rel *Relation, it Iterator) error {
+	return WriteCSV(dst, rel, &contextIterator{Iterator: it, ctx: ctx})
+}
+
+// WriteJSONL writes rows to dst as JSON objects keyed by attribute name,
+// one per line. Missing values are encoded as null, dates as ISO8601
+// strings and weights are omitted.
+func WriteJSONL(dst io.Writer, rel *Relation, it Iterator) error {
+	w := bufio.NewWriter(dst)
+
+	keys := make([][]byte, len(rel.Attributes))
+	for i, attr := range rel.Attributes {
+		key, err := json.Marshal(attr.Name)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	for it.Next() {
+		row := it.Row()
+		if len(row.Values) != len(keys) {
+			return errAttrMismatch
+		}
+
+		_ = w.WriteByte('{')
+		for i, v := range row.Values {
+			if i != 0 {
+				_ = w.WriteByte(',')
+			}
+			_, _ = w.Write(keys[i])
+			_ = w.WriteByte(':')
+
+			if t, ok := v.(time.Time); ok {
+				v = t.UTC().Format(iso8691DateFormat)
+			}
+			val, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			_, _ = w.Write(val)
+		}
+		if _, err := w.WriteString("}\n"); err != nil {
+			return err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// WriteJSONLContext writes rows to dst as JSON objects, like WriteJSONL,
+// but stops once ctx is done
+func WriteJSONLContext(ctx context.Context, dst io.Writer, rel *Relation, it Iterator) error {
+	return WriteJSONL(dst, rel, &contextIterator{Iterator: it, ctx: ctx})
+}
+
+// ReadCSV reads CSV data with a header line of attribute names into a
+// Dataset called name. Data types are inferred from the values: columns
+// are numeric or date if all of their values parse as such and nominal
+// otherwise. Empty fields and '?' are treated as missing.
+func ReadCSV(src io.Reader, name string) (*Dataset, error) {
+	return ReadCSVContext(context.Background(), src, name)
+}
+
+// ReadCSVContext reads CSV data into a Dataset, like ReadCSV, but stops
+// once ctx is done
+func ReadCSVContext(ctx context.Context, src io.Reader, name string) (*Dataset, error) {
+	var check contextCheck
+	r := csv.NewReader(src)
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, errMissingAttrName
+	} else if err != nil {
+		return nil, err
+	}
+
+	var records [][]interface{}
+	for {
+		if err := check.Err(ctx); err != nil {
+			return nil, err
+		}
+
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		values := make([]interface{}, len(record))
+		for i, s := range record {
+			if s != "" && s != "?" {
+				values[i] = s
+			}
+		}
+		records = append(records, values)
+	}
+	return inferDataset(name, header, records)
+}
+
+// ReadJSONL reads newline-delimited JSON objects into a Dataset called
+// name. Attributes are created in the order in which keys are first seen,
+// absent keys and nulls are treated as missing values. Data types are
+// inferred as in ReadCSV.
+func ReadJSONL(src io.Reader, name string) (*Dataset, error) {
+	return ReadJSONLContext(context.Background(), src, name)
+}
+
+// ReadJSONLContext reads newline-delimited JSON objects into a Dataset,
+// like ReadJSONL, but stops once ctx is done
+func ReadJSONLContext(ctx context.Context, src io.Reader, name string) (*Dataset, error) {
+	var check contextCheck
+	var names []string
+	var records []map[string]interface{}
+
+	index := make(map[string]struct{})
+	dec := json.NewDecoder(src)
+	dec.UseNumber()
+
+	for {
+		if err := check.Err(ctx); err != nil {
+			return nil, err
+		}
+
+		if tok, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		} else if tok != json.Delim('{') {
+			return nil, fmt.Errorf("expected JSON object, got %v", tok)
+		}
+
+		record := make(map[string]interface{})
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := tok.(string)
+
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				return nil, err
+			}
+			switch vv := v.(type) {
+			case nil:
+			case json.Number:
+				record[key] = string(vv)
+			case string:
+				record[key] = vv
+			case bool:
+				record[key] = strconv.FormatBool(vv)
+			default:
+				return nil, fmt.Errorf("value of '%s' is not a scalar", key)
+			}
+
+			if _, ok := index[key]; !ok {
+				index[key] = struct{}{}
+				names = append(names, key)
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	values := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		row := make([]interface{}, len(names))
+		for i, name := range names {
+			if v, ok := record[name]; ok {
+				row[i] = v
+			}
+		}
+		values = append(values, row)
+	}
+	return inferDataset(name, names, values)
+}
+
+// --------------------------------------------------------------------
+
+func formatValue(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+
+	buf := new(writeBuffer)
+	if t, ok := v.(time.Time); ok {
+		_ = buf.WriteTime(t)
+	} else if s, ok := v.(string); ok {
+		return s, nil
+	} else if err := buf.WriteRowValue(v); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// inferDataset builds a dataset from string values, inferring attribute
+// data types; missing values must be nil
+func inferDataset(name string, names []string, records [][]interface{}) (*Dataset, error) {
+	data := &Dataset{Relation: Relation{Name: name}}
+	for _, name := range names {
+		if err := data.AddAttribute(name, DataTypeString, nil); err != nil {
+			return nil, fmt.Errorf("%s '%s'", err.Error(), name)
+		}
+	}
+
+	for i := range data.Attributes {
+		attr := &data.Attributes[i]
+		attr.DataType = inferDataType(records, i)
+
+		seen := make(map[string]bool)
+		for _, values := range records {
+			s, ok := values[i].(string)
+			if !ok {
+				continue
+			}
+
+			switch attr.DataType {
+			case DataTypeNumeric, DataTypeDate:
+				v, err := attr.parse(s)
+				if err != nil {
+					return nil, err
+				}
+				values[i] = v
+			case DataTypeNominal:
+				if !seen[s] {
+					seen[s] = true
+					attr.NominalValues = append(attr.NominalValues, s)
+				}
+			}
+		}
+	}
+
+	data.Rows = make([]DataRow, len(records))
+	for i, values := range records {
+		data.Rows[i].Values = values
+	}
+	if err := data.validate(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func inferDataType(records [][]interface{}, i int) DataType {
+	numeric, date, present := true, true, false
+	for _, values := range records {
+		s, ok := values[i].(string)
+		if !ok {
+			continue
+		}
+		present = true
+
+		if numeric {
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				numeric = false
+			}
+		}
+		if date {
+			if _, err := time.ParseInLocation(iso8691DateFormat, s, utc); err != nil {
+				date = false
+			}
+		}
+		if !numeric && !date {
+			return DataTypeNominal
+		}
+	}
+
+	switch {
+	case !present:
+		return DataTypeString
+	case numeric:
+		return DataTypeNumeric
+	case date:
+		return DataTypeDate
+	}
+	return DataTypeNominal
+}
@@ -0,0 +1,132 @@
+package arff
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WriteCSV", func() {
+
+	It("should write CSV", func() {
+		data, err := OpenDataset("testdata/messy.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		Expect(WriteCSV(buf, &data.Relation, data.Iterator())).To(Succeed())
+		Expect(buf.String()).To(Equal(`fo{o},bar,baz,bon,boo
+1,x,2014-10-24T09:03:34,7,"ruby
+red"
+2.3,y,,6,green
+-0.6,?,,5,light blue
+`))
+	})
+
+	It("should stop once cancelled", func() {
+		data, err := OpenDataset("testdata/messy.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		buf := new(bytes.Buffer)
+		Expect(WriteCSVContext(ctx, buf, &data.Relation, data.Iterator())).To(Equal(context.Canceled))
+		Expect(WriteJSONLContext(ctx, buf, &data.Relation, data.Iterator())).To(Equal(context.Canceled))
+		Expect(WriteCSVContext(context.Background(), buf, &data.Relation, data.Iterator())).To(Succeed())
+	})
+
+})
+
+var _ = Describe("WriteJSONL", func() {
+
+	It("should write JSONL", func() {
+		data, err := OpenDataset("testdata/messy.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		Expect(WriteJSONL(buf, &data.Relation, data.Iterator())).To(Succeed())
+		Expect(buf.String()).To(Equal(`{"fo{o}":1,"bar":"x","baz":"2014-10-24T09:03:34","bon":7,"boo":"ruby\nred"}
+{"fo{o}":2.3,"bar":"y","baz":null,"bon":6,"boo":"green"}
+{"fo{o}":-0.6,"bar":"?","baz":null,"bon":5,"boo":"light blue"}
+`))
+	})
+
+})
+
+var _ = Describe("ReadCSV", func() {
+
+	It("should infer data types", func() {
+		data, err := ReadCSV(strings.NewReader("num,date,nom,none\n1.5,2014-10-24T09:03:34,b,\n?,,a,\n-2,2014-10-25T00:00:00,b,?\n"), "csv")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Relation).To(Equal(Relation{
+			Name: "csv",
+			Attributes: []Attribute{
+				{Name: "num", DataType: DataTypeNumeric},
+				{Name: "date", DataType: DataTypeDate},
+				{Name: "nom", DataType: DataTypeNominal, NominalValues: []string{"b", "a"}},
+				{Name: "none", DataType: DataTypeString},
+			},
+		}))
+		Expect(data.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{1.5, time.Unix(1414141414, 0).UTC(), "b", nil}},
+			{Values: []interface{}{nil, nil, "a", nil}},
+			{Values: []interface{}{-2.0, time.Date(2014, 10, 25, 0, 0, 0, 0, time.UTC), "b", nil}},
+		}))
+	})
+
+	It("should reject duplicate attributes", func() {
+		_, err := ReadCSV(strings.NewReader("a,a\n1,2\n"), "csv")
+		Expect(err).To(MatchError("redefined attribute 'a'"))
+	})
+
+	It("should stop once cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := ReadCSVContext(ctx, strings.NewReader("a\n1\n"), "csv")
+		Expect(err).To(Equal(context.Canceled))
+	})
+
+})
+
+var _ = Describe("ReadJSONL", func() {
+
+	It("should infer data types", func() {
+		data, err := ReadJSONL(strings.NewReader(`{"num":1.5,"bool":true}
+{"str":"x","num":null}
+{"bool":false,"num":2}
+`), "json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Relation).To(Equal(Relation{
+			Name: "json",
+			Attributes: []Attribute{
+				{Name: "num", DataType: DataTypeNumeric},
+				{Name: "bool", DataType: DataTypeNominal, NominalValues: []string{"true", "false"}},
+				{Name: "str", DataType: DataTypeNominal, NominalValues: []string{"x"}},
+			},
+		}))
+		Expect(data.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{1.5, "true", nil}},
+			{Values: []interface{}{nil, nil, "x"}},
+			{Values: []interface{}{2.0, "false", nil}},
+		}))
+	})
+
+	It("should reject nested values", func() {
+		_, err := ReadJSONL(strings.NewReader(`{"a":[1]}`), "json")
+		Expect(err).To(MatchError("value of 'a' is not a scalar"))
+	})
+
+	It("should stop once cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := ReadJSONLContext(ctx, strings.NewReader(`{"a":1}`), "json")
+		Expect(err).To(Equal(context.Canceled))
+	})
+
+})
@@ -0,0 +1,103 @@
+package arff
+
+import (
+	"context"
+	"io"
+)
+
+// Dataset holds a relation together with all of its data rows in memory
+type Dataset struct {
+	Relation
+	Rows []DataRow
+}
+
+// ReadDataset reads the relation and all remaining rows of r
+func ReadDataset(r *Reader) (*Dataset, error) {
+	return ReadDatasetContext(r.context(), r)
+}
+
+// ReadDatasetContext reads the relation and all remaining rows of r, like
+// ReadDataset, but stops once ctx is done
+func ReadDatasetContext(ctx context.Context, r *Reader) (*Dataset, error) {
+	rows, err := r.ReadAllContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Dataset{Relation: r.Relation, Rows: rows}, nil
+}
+
+// OpenDataset reads the file at location into a Dataset
+func OpenDataset(fname string) (*Dataset, error) {
+	r, err := Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ReadDataset(r)
+}
+
+// Iterator returns an iterator over the dataset rows
+func (d *Dataset) Iterator() Iterator {
+	return &sliceIterator{rows: d.Rows}
+}
+
+// WriteTo writes the dataset in ARFF format to dst
+func (d *Dataset) WriteTo(dst io.Writer) (int64, error) {
+	cw := &countingWriter{Writer: dst}
+
+	w, err := NewWriterSize(cw, &d.Relation, DefaultBufferSize)
+	if err != nil {
+		return cw.N, err
+	}
+	if err := w.AppendAll(d.Iterator()); err != nil {
+		return cw.N, err
+	}
+	err = w.Close()
+	return cw.N, err
+}
+
+// WriteFile atomically writes the dataset to the file at location
+func (d *Dataset) WriteFile(fname string) error {
+	w, err := CreateAtomic(fname, &d.Relation)
+	if err != nil {
+		return err
+	}
+	if err := w.AppendAll(d.Iterator()); err != nil {
+		_ = w.Abort()
+		return err
+	}
+	return w.Close()
+}
+
+// --------------------------------------------------------------------
+
+type sliceIterator struct {
+	rows []DataRow
+	pos  int
+	row  *DataRow
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.pos >= len(it.rows) {
+		it.row = nil
+		return false
+	}
+	it.row = &it.rows[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *sliceIterator) Row() *DataRow { return it.row }
+func (it *sliceIterator) Err() error    { return nil }
+
+type countingWriter struct {
+	io.Writer
+	N int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.N += int64(n)
+	return n, err
+}
@@ -0,0 +1,80 @@
+package arff
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dataset", func() {
+
+	It("should read datasets", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Name).To(Equal("weather"))
+		Expect(data.Attributes).To(HaveLen(5))
+		Expect(data.Rows).To(HaveLen(14))
+		Expect(data.Rows[0].Values).To(Equal([]interface{}{"sunny", 85.0, 85.0, "FALSE", "no"}))
+	})
+
+	It("should stop reading once cancelled", func() {
+		r, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = ReadDatasetContext(ctx, r)
+		Expect(err).To(Equal(context.Canceled))
+	})
+
+	It("should iterate", func() {
+		data := &Dataset{Rows: []DataRow{
+			{Values: []interface{}{1.0}},
+			{Values: []interface{}{2.0}},
+		}}
+
+		var vals []interface{}
+		it := data.Iterator()
+		for it.Next() {
+			vals = append(vals, it.Row().Values...)
+		}
+		Expect(it.Err()).NotTo(HaveOccurred())
+		Expect(it.Row()).To(BeNil())
+		Expect(vals).To(Equal([]interface{}{1.0, 2.0}))
+	})
+
+	It("should write", func() {
+		data, err := OpenDataset("testdata/messy.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		n, err := data.WriteTo(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(buf.Len())))
+
+		bin, err := ioutil.ReadFile("testdata/messy.arff")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.String()).To(Equal(string(bin)))
+	})
+
+	It("should write files", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		dir, err := ioutil.TempDir("", "arff-test")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		fname := filepath.Join(dir, "weather.arff")
+		Expect(data.WriteFile(fname)).To(Succeed())
+		Expect(OpenDataset(fname)).To(Equal(data))
+	})
+
+})
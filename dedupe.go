@@ -0,0 +1,231 @@
+package arff
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// DedupeMode defines which of the duplicate rows is retained
+type DedupeMode uint8
+
+const (
+	// KeepFirst retains the first occurrence
+	KeepFirst DedupeMode = iota
+	// KeepLast retains the last occurrence
+	KeepLast
+	// SumWeights retains the first occurrence with the sum of the weights
+	// of all occurrences
+	SumWeights
+)
+
+// DedupeOptions configure Dedupe
+type DedupeOptions struct {
+	// Attributes are the names of the attributes which identify
+	// duplicates. Default: all attributes
+	Attributes []string
+
+	// Mode defines which of the duplicate rows is retained.
+	// Default: KeepFirst
+	Mode DedupeMode
+
+	// MaxRows is the maximum number of unique rows held in memory.
+	// Default: 1,000,000
+	MaxRows int
+
+	// Buckets is the number of temporary files rows are partitioned
+	// into when they don't fit in memory. Default: 16
+	Buckets int
+
+	// TempDir is the directory for temporary files.
+	// Default: os.TempDir()
+	TempDir string
+}
+
+func (o *DedupeOptions) norm() *DedupeOptions {
+	var oo DedupeOptions
+	if o != nil {
+		oo = *o
+	}
+	if oo.MaxRows < 1 {
+		oo.MaxRows = 1000000
+	}
+	if oo.Buckets < 2 {
+		oo.Buckets = 16
+	}
+	return &oo
+}
+
+// DedupeStats reports the result of Dedupe
+type DedupeStats struct {
+	// Rows is the number of input rows
+	Rows int
+	// Unique is the number of output rows
+	Unique int
+	// Duplicates is the number of discarded rows
+	Duplicates int
+}
+
+// Dedupe writes all remaining rows of src to dst, omitting duplicates.
+// Missing values are equal to each other. Unique rows are written in the
+// order of their first occurrence. Inputs with more than MaxRows unique
+// rows are partitioned by key hash into temporary ARFF buckets on disk,
+// which are deduplicated individually; the output order is then only
+// preserved within each bucket.
+func Dedupe(dst *Writer, src *Reader, opt *DedupeOptions) (*DedupeStats, error) {
+	opt = opt.norm()
+
+	index := make([]int, 0, len(src.Attributes))
+	for _, name := range opt.Attributes {
+		pos := src.AttributeIndex(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+		index = append(index, pos)
+	}
+	if len(opt.Attributes) == 0 {
+		for pos := range src.Attributes {
+			index = append(index, pos)
+		}
+	}
+
+	d := &deduper{DedupeOptions: opt, rel: &src.Relation, index: index}
+	if err := d.Dedupe(dst, src, 0); err != nil {
+		return nil, err
+	}
+	d.stats.Duplicates = d.stats.Rows - d.stats.Unique
+	return &d.stats, nil
+}
+
+// maxDedupeDepth limits the recursion of bucket partitioning
+const maxDedupeDepth = 4
+
+type deduper struct {
+	*DedupeOptions
+	rel   *Relation
+	index []int
+	stats DedupeStats
+}
+
+func (d *deduper) Dedupe(dst *Writer, src Iterator, depth int) error {
+	seen := make(map[string]int)
+	var rows []DataRow
+
+	for (len(rows) < d.MaxRows || depth >= maxDedupeDepth) && src.Next() {
+		row := src.Row()
+		if depth == 0 {
+			d.stats.Rows++
+		}
+		d.add(&rows, seen, row)
+	}
+	if err := src.Err(); err != nil {
+		return err
+	}
+
+	// deduplicate in memory when the input is small enough
+	if len(rows) < d.MaxRows || depth >= maxDedupeDepth || !src.Next() {
+		if err := src.Err(); err != nil {
+			return err
+		}
+		for i := range rows {
+			if err := dst.Append(&rows[i]); err != nil {
+				return err
+			}
+		}
+		d.stats.Unique += len(rows)
+		return nil
+	}
+
+	// otherwise, partition rows into buckets
+	buckets := make([]*tempFile, d.Buckets)
+	defer func() {
+		for _, b := range buckets {
+			if b != nil {
+				_ = b.Remove()
+			}
+		}
+	}()
+
+	for i := range buckets {
+		b, err := createTempFile(d.TempDir, "arff-dedupe-", d.rel)
+		if err != nil {
+			return err
+		}
+		buckets[i] = b
+	}
+	for i := range rows {
+		if err := buckets[d.bucket(&rows[i], depth)].Append(&rows[i]); err != nil {
+			return err
+		}
+	}
+	rows, seen = nil, nil
+
+	for ok := true; ok; ok = src.Next() {
+		row := src.Row()
+		if depth == 0 {
+			d.stats.Rows++
+		}
+		if err := buckets[d.bucket(row, depth)].Append(row); err != nil {
+			return err
+		}
+	}
+	if err := src.Err(); err != nil {
+		return err
+	}
+
+	for i, b := range buckets {
+		if err := d.dedupeBucket(dst, b, depth+1); err != nil {
+			return err
+		}
+		buckets[i] = nil
+	}
+	return nil
+}
+
+// add appends row to rows unless it is a duplicate, in which case the
+// retained row is updated according to Mode
+func (d *deduper) add(rows *[]DataRow, seen map[string]int, row *DataRow) {
+	key := d.key(row)
+	i, ok := seen[key]
+	if !ok {
+		seen[key] = len(*rows)
+		*rows = append(*rows, *row)
+		return
+	}
+
+	kept := &(*rows)[i]
+	switch d.Mode {
+	case KeepLast:
+		*kept = *row
+	case SumWeights:
+		kept.Weight = kept.weight() + row.weight()
+	}
+}
+
+func (d *deduper) dedupeBucket(dst *Writer, b *tempFile, depth int) error {
+	defer b.Remove()
+
+	r, err := b.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return d.Dedupe(dst, r, depth)
+}
+
+func (d *deduper) key(row *DataRow) string {
+	var b strings.Builder
+	for _, pos := range d.index {
+		writeKey(&b, row.Values[pos])
+	}
+	return b.String()
+}
+
+// bucket returns the bucket of row, salted by depth
+func (d *deduper) bucket(row *DataRow, depth int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte{byte(depth)})
+	_, _ = h.Write([]byte(d.key(row)))
+	return int(h.Sum32() % uint32(d.Buckets))
+}
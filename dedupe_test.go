@@ -0,0 +1,112 @@
+package arff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dedupe", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "arff-test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	input := "@relation dupes\n@attribute k NUMERIC\n@attribute v STRING\n@data\n" +
+		"1,a\n2,b\n1,a,{2}\n3,c\n1,x\n?,d\n2,b\n?,d\n-0,z\n0,z\n"
+
+	dedupe := func(opt *DedupeOptions) (*Dataset, *DedupeStats) {
+		src, err := NewReader(bytes.NewBufferString(input))
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		dst, err := NewWriter(buf, &src.Relation)
+		Expect(err).NotTo(HaveOccurred())
+		stats, err := Dedupe(dst, src, opt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dst.Close()).To(Succeed())
+
+		data, err := readDataset(buf)
+		Expect(err).NotTo(HaveOccurred())
+		return data, stats
+	}
+
+	It("should keep first occurrences", func() {
+		data, stats := dedupe(nil)
+		Expect(stats).To(Equal(&DedupeStats{Rows: 10, Unique: 6, Duplicates: 4}))
+		Expect(data.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{1.0, "a"}},
+			{Values: []interface{}{2.0, "b"}},
+			{Values: []interface{}{3.0, "c"}},
+			{Values: []interface{}{1.0, "x"}},
+			{Values: []interface{}{nil, "d"}},
+			{Values: []interface{}{0.0, "z"}},
+		}))
+	})
+
+	It("should keep last occurrences", func() {
+		data, stats := dedupe(&DedupeOptions{Attributes: []string{"k"}, Mode: KeepLast})
+		Expect(stats).To(Equal(&DedupeStats{Rows: 10, Unique: 5, Duplicates: 5}))
+		Expect(data.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{1.0, "x"}},
+			{Values: []interface{}{2.0, "b"}},
+			{Values: []interface{}{3.0, "c"}},
+			{Values: []interface{}{nil, "d"}},
+			{Values: []interface{}{0.0, "z"}},
+		}))
+	})
+
+	It("should sum weights", func() {
+		data, _ := dedupe(&DedupeOptions{Mode: SumWeights})
+		Expect(data.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{1.0, "a"}, Weight: 3},
+			{Values: []interface{}{2.0, "b"}, Weight: 2},
+			{Values: []interface{}{3.0, "c"}},
+			{Values: []interface{}{1.0, "x"}},
+			{Values: []interface{}{nil, "d"}, Weight: 2},
+			{Values: []interface{}{0.0, "z"}, Weight: 2},
+		}))
+	})
+
+	It("should dedupe out of core", func() {
+		inMemory, _ := dedupe(&DedupeOptions{Mode: SumWeights})
+
+		data, stats := dedupe(&DedupeOptions{Mode: SumWeights, MaxRows: 2, Buckets: 2, TempDir: dir})
+		Expect(stats).To(Equal(&DedupeStats{Rows: 10, Unique: 6, Duplicates: 4}))
+		Expect(data.Rows).To(ConsistOf(inMemory.Rows))
+
+		data, _ = dedupe(&DedupeOptions{Attributes: []string{"k"}, Mode: KeepLast, MaxRows: 2, Buckets: 2, TempDir: dir})
+		Expect(data.Rows).To(ConsistOf(
+			DataRow{Values: []interface{}{1.0, "x"}},
+			DataRow{Values: []interface{}{2.0, "b"}},
+			DataRow{Values: []interface{}{3.0, "c"}},
+			DataRow{Values: []interface{}{nil, "d"}},
+			DataRow{Values: []interface{}{0.0, "z"}},
+		))
+
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+
+	It("should validate attributes", func() {
+		src, err := NewReader(bytes.NewBufferString(input))
+		Expect(err).NotTo(HaveOccurred())
+		dst, err := NewWriter(new(bytes.Buffer), &src.Relation)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = Dedupe(dst, src, &DedupeOptions{Attributes: []string{"x"}})
+		Expect(err).To(MatchError("unknown attribute 'x'"))
+	})
+
+})
@@ -0,0 +1,100 @@
+package arff
+
+import "fmt"
+
+// DerivedAttribute defines an attribute computed from row values
+type DerivedAttribute struct {
+	// Name is the attribute name
+	Name string
+
+	// Expr is an expression computing the value, see Expr. The data type
+	// is derived from the expression, boolean values are stored as
+	// nominal 'false' or 'true'. Expressions may refer to attributes
+	// derived before.
+	Expr string
+
+	// Func computes the value for rows if Expr is blank. DataType and
+	// NominalValues must be set accordingly.
+	Func func(row *DataRow) (interface{}, error)
+
+	// DataType is the data type of values computed by Func
+	DataType DataType
+
+	// NominalValues are the labels of nominal values computed by Func
+	NominalValues []string
+}
+
+// Derive is a Filter which appends derived attributes to rows, e.g.
+//
+//	&Derive{Attributes: []DerivedAttribute{
+//		{Name: "ratio", Expr: "temperature / humidity"},
+//		{Name: "year", Expr: "year(date)"},
+//	}}
+type Derive struct {
+	Attributes []DerivedAttribute
+
+	funcs []func(*DataRow) (interface{}, error)
+}
+
+// Init implements Filter
+func (f *Derive) Init(in *Relation) (*Relation, error) {
+	out := *in
+	out.Attributes = append([]Attribute(nil), in.Attributes...)
+	out.Class = in.className()
+
+	f.funcs = f.funcs[:0]
+	for _, d := range f.Attributes {
+		dataType, labels, fn := d.DataType, d.NominalValues, d.Func
+		if d.Expr != "" {
+			expr, err := Compile(&out, d.Expr)
+			if err != nil {
+				return nil, err
+			}
+			dataType, labels, fn = expr.derive()
+		} else if fn == nil {
+			return nil, fmt.Errorf("%s '%s'", errMissingExpr.Error(), d.Name)
+		}
+
+		if err := out.AddAttribute(d.Name, dataType, labels); err != nil {
+			return nil, fmt.Errorf("%s '%s'", err.Error(), d.Name)
+		}
+		f.funcs = append(f.funcs, fn)
+	}
+	return &out, nil
+}
+
+// Apply implements Filter
+func (f *Derive) Apply(row *DataRow) (*DataRow, error) {
+	values := make([]interface{}, len(row.Values), len(row.Values)+len(f.funcs))
+	copy(values, row.Values)
+	out := &DataRow{Values: values, Weight: row.Weight}
+
+	for _, fn := range f.funcs {
+		v, err := fn(out)
+		if err != nil {
+			return nil, err
+		}
+		out.Values = append(out.Values, v)
+	}
+	return out, nil
+}
+
+// derive returns the data type, labels and function of a derived attribute
+func (e *Expr) derive() (DataType, []string, func(*DataRow) (interface{}, error)) {
+	eval := func(row *DataRow) (interface{}, error) { return e.Eval(row), nil }
+
+	switch e.node.typ {
+	case exprNumeric:
+		return DataTypeNumeric, nil, eval
+	case exprDate:
+		return DataTypeDate, nil, eval
+	case exprBool:
+		return DataTypeNominal, []string{"false", "true"}, func(row *DataRow) (interface{}, error) {
+			if b, ok := e.Eval(row).(bool); ok {
+				return fmt.Sprint(b), nil
+			}
+			return nil, nil
+		}
+	}
+	return DataTypeString, nil, eval
+}
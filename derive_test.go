@@ -0,0 +1,75 @@
+package arff
+
+import (
+	"bytes"
+	"errors"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Derive", func() {
+
+	It("should append derived attributes", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		out, err := data.Filter(&Derive{Attributes: []DerivedAttribute{
+			{Name: "ratio", Expr: "temperature / humidity"},
+			{Name: "log_ratio", Expr: "log(ratio)"},
+			{Name: "hot", Expr: "temperature > 80"},
+			{Name: "label", Expr: "upper(outlook)"},
+			{Name: "weight", DataType: DataTypeNumeric, Func: func(row *DataRow) (interface{}, error) {
+				return row.weight(), nil
+			}},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes[5:]).To(Equal([]Attribute{
+			{Name: "ratio", DataType: DataTypeNumeric},
+			{Name: "log_ratio", DataType: DataTypeNumeric},
+			{Name: "hot", DataType: DataTypeNominal, NominalValues: []string{"false", "true"}},
+			{Name: "label", DataType: DataTypeString},
+			{Name: "weight", DataType: DataTypeNumeric},
+		}))
+		Expect(out.Class).To(Equal("play"))
+		Expect(data.Attributes).To(HaveLen(5))
+		Expect(data.Rows[0].Values).To(HaveLen(5))
+
+		Expect(out.Rows[0].Values).To(Equal([]interface{}{"sunny", 85.0, 85.0, "FALSE", "no", 1.0, 0.0, "true", "SUNNY", 1.0}))
+		Expect(out.Rows[1].Values[6]).To(BeNumerically("~", math.Log(80.0/90.0), 1e-9))
+		Expect(out.Rows[1].Values[7]).To(Equal("false"))
+	})
+
+	It("should validate", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = data.Filter(&Derive{Attributes: []DerivedAttribute{{Name: "play", Expr: "1"}}})
+		Expect(err).To(MatchError("redefined attribute 'play'"))
+
+		_, err = data.Filter(&Derive{Attributes: []DerivedAttribute{{Name: "x", Expr: "windy + 1"}}})
+		Expect(err).To(MatchError("bad expression at 7: cannot apply '+' to string and numeric"))
+
+		_, err = data.Filter(&Derive{Attributes: []DerivedAttribute{{Name: "x"}}})
+		Expect(err).To(MatchError("missing expression 'x'"))
+
+		_, err = data.Filter(&Derive{Attributes: []DerivedAttribute{{Name: "x", Func: func(*DataRow) (interface{}, error) {
+			return nil, errors.New("failed")
+		}}}})
+		Expect(err).To(MatchError("failed"))
+	})
+
+	It("should stream between reader and writer", func() {
+		r, err := NewReader(bytes.NewBufferString("@relation events\n@attribute at DATE\n@data\n2018-06-01T12:00:00\n?\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		Expect(ApplyFilters(buf, r, &Derive{Attributes: []DerivedAttribute{
+			{Name: "year", Expr: "year(at)"},
+			{Name: "weekday", Expr: "weekday(at)"},
+		}})).To(Succeed())
+		Expect(buf.String()).To(HaveSuffix("@ATTRIBUTE at DATE\n@ATTRIBUTE year NUMERIC\n@ATTRIBUTE weekday NUMERIC\n\n@DATA\n2018-06-01T12:00:00,2018,5\n?,?,?\n"))
+	})
+
+})
@@ -0,0 +1,197 @@
+package arff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Compatibility classifies the difference between two relations
+type Compatibility uint8
+
+const (
+	// Identical relations define the same attributes in the same order
+	Identical Compatibility = iota
+	// BackwardsCompatible relations only extend nominal value sets, so data
+	// of the old relation is also valid in the new one
+	BackwardsCompatible
+	// Incompatible relations differ in ways that invalidate existing data
+	Incompatible
+)
+
+// String returns the compatibility name
+func (c Compatibility) String() string {
+	switch c {
+	case Identical:
+		return "identical"
+	case BackwardsCompatible:
+		return "backwards-compatible"
+	case Incompatible:
+		return "incompatible"
+	}
+	return "Compatibility(" + strconv.Itoa(int(c)) + ")"
+}
+
+// ChangeKind is the type of a SchemaChange
+type ChangeKind uint8
+
+const (
+	// AttributeAdded indicates a new attribute
+	AttributeAdded ChangeKind = iota
+	// AttributeRemoved indicates a removed attribute
+	AttributeRemoved
+	// AttributeRetyped indicates a data type change
+	AttributeRetyped
+	// AttributeMoved indicates a change of attribute order
+	AttributeMoved
+	// LabelsAdded indicates new nominal values
+	LabelsAdded
+	// LabelsRemoved indicates removed nominal values
+	LabelsRemoved
+	// LabelsReordered indicates a change of nominal value order
+	LabelsReordered
+)
+
+// SchemaChange describes a difference of a single attribute
+type SchemaChange struct {
+	Kind      ChangeKind
+	Attribute string
+
+	// OldIndex and NewIndex are the attribute positions, -1 if absent
+	OldIndex, NewIndex int
+	// OldType and NewType are the data types
+	OldType, NewType DataType
+	// Labels are the added or removed nominal values
+	Labels []string
+}
+
+// String returns a human readable description
+func (c *SchemaChange) String() string {
+	switch c.Kind {
+	case AttributeAdded:
+		return fmt.Sprintf("attribute '%s' added at %d", c.Attribute, c.NewIndex)
+	case AttributeRemoved:
+		return fmt.Sprintf("attribute '%s' removed from %d", c.Attribute, c.OldIndex)
+	case AttributeRetyped:
+		return fmt.Sprintf("attribute '%s' changed from %s to %s", c.Attribute, c.OldType, c.NewType)
+	case AttributeMoved:
+		return fmt.Sprintf("attribute '%s' moved from %d to %d", c.Attribute, c.OldIndex, c.NewIndex)
+	case LabelsAdded:
+		return fmt.Sprintf("attribute '%s' added labels %s", c.Attribute, strings.Join(c.Labels, ","))
+	case LabelsRemoved:
+		return fmt.Sprintf("attribute '%s' removed labels %s", c.Attribute, strings.Join(c.Labels, ","))
+	case LabelsReordered:
+		return fmt.Sprintf("attribute '%s' reordered labels", c.Attribute)
+	}
+	return fmt.Sprintf("attribute '%s' changed", c.Attribute)
+}
+
+// SchemaDiff is the result of a relation comparison
+type SchemaDiff struct {
+	Changes       []SchemaChange
+	Compatibility Compatibility
+}
+
+// Diff compares the attributes of r with those of a newer relation.
+// Relation names and comments are ignored.
+func (r *Relation) Diff(newer *Relation) *SchemaDiff {
+	diff := new(SchemaDiff)
+
+	// attributes present in both, in old and in new order
+	var oldCommon, newCommon []string
+
+	for i, attr := range r.Attributes {
+		j := newer.AttributeIndex(attr.Name)
+		if j < 0 {
+			diff.add(SchemaChange{Kind: AttributeRemoved, Attribute: attr.Name, OldIndex: i, NewIndex: -1, OldType: attr.DataType})
+			continue
+		}
+		oldCommon = append(oldCommon, attr.Name)
+
+		other := newer.Attributes[j]
+		if other.DataType != attr.DataType {
+			diff.add(SchemaChange{Kind: AttributeRetyped, Attribute: attr.Name, OldIndex: i, NewIndex: j, OldType: attr.DataType, NewType: other.DataType})
+		} else if attr.DataType == DataTypeNominal {
+			diff.diffLabels(&attr, &other, i, j)
+		}
+	}
+
+	for j, attr := range newer.Attributes {
+		if r.AttributeIndex(attr.Name) < 0 {
+			diff.add(SchemaChange{Kind: AttributeAdded, Attribute: attr.Name, OldIndex: -1, NewIndex: j, NewType: attr.DataType})
+		} else {
+			newCommon = append(newCommon, attr.Name)
+		}
+	}
+
+	// report attributes which changed their relative and absolute position
+	for n, name := range oldCommon {
+		i, j := r.AttributeIndex(name), newer.AttributeIndex(name)
+		if newCommon[n] != name && i != j {
+			dt := newer.Attributes[j].DataType
+			diff.add(SchemaChange{Kind: AttributeMoved, Attribute: name, OldIndex: i, NewIndex: j, OldType: dt, NewType: dt})
+		}
+	}
+	return diff
+}
+
+func (d *SchemaDiff) diffLabels(old, newer *Attribute, i, j int) {
+	change := SchemaChange{Attribute: old.Name, OldIndex: i, NewIndex: j, OldType: old.DataType, NewType: newer.DataType}
+
+	var removed, added []string
+	for _, v := range old.NominalValues {
+		if indexOf(newer.NominalValues, v) < 0 {
+			removed = append(removed, v)
+		}
+	}
+	for _, v := range newer.NominalValues {
+		if indexOf(old.NominalValues, v) < 0 {
+			added = append(added, v)
+		}
+	}
+
+	if len(removed) != 0 {
+		change.Kind, change.Labels = LabelsRemoved, removed
+		d.add(change)
+	}
+	if len(added) != 0 {
+		change.Kind, change.Labels = LabelsAdded, added
+		d.add(change)
+	}
+
+	// compare the relative order of retained labels
+	last := -1
+	for _, v := range old.NominalValues {
+		m := indexOf(newer.NominalValues, v)
+		if m < 0 {
+			continue
+		}
+		if m < last {
+			change.Kind, change.Labels = LabelsReordered, nil
+			d.add(change)
+			return
+		}
+		last = m
+	}
+}
+
+func (d *SchemaDiff) add(change SchemaChange) {
+	d.Changes = append(d.Changes, change)
+
+	level := Incompatible
+	if change.Kind == LabelsAdded {
+		level = BackwardsCompatible
+	}
+	if level > d.Compatibility {
+		d.Compatibility = level
+	}
+}
+
+func indexOf(vals []string, s string) int {
+	for i, v := range vals {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,63 @@
+package arff
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Relation.Diff", func() {
+	var base *Relation
+
+	BeforeEach(func() {
+		base = &Relation{
+			Name: "base",
+			Attributes: []Attribute{
+				{Name: "a", DataType: DataTypeNumeric},
+				{Name: "b", DataType: DataTypeNominal, NominalValues: []string{"x", "y", "z"}},
+				{Name: "c", DataType: DataTypeString},
+			},
+		}
+	})
+
+	It("should detect identical relations", func() {
+		diff := base.Diff(&Relation{Name: "other", Attributes: base.Attributes})
+		Expect(diff.Changes).To(BeEmpty())
+		Expect(diff.Compatibility).To(Equal(Identical))
+		Expect(diff.Compatibility.String()).To(Equal("identical"))
+	})
+
+	It("should detect new labels", func() {
+		diff := base.Diff(&Relation{Attributes: []Attribute{
+			{Name: "a", DataType: DataTypeNumeric},
+			{Name: "b", DataType: DataTypeNominal, NominalValues: []string{"x", "y", "z", "w"}},
+			{Name: "c", DataType: DataTypeString},
+		}})
+		Expect(diff.Changes).To(Equal([]SchemaChange{
+			{Kind: LabelsAdded, Attribute: "b", OldIndex: 1, NewIndex: 1, OldType: DataTypeNominal, NewType: DataTypeNominal, Labels: []string{"w"}},
+		}))
+		Expect(diff.Compatibility).To(Equal(BackwardsCompatible))
+	})
+
+	It("should detect incompatible changes", func() {
+		diff := base.Diff(&Relation{Attributes: []Attribute{
+			{Name: "c", DataType: DataTypeNominal, NominalValues: []string{"p"}},
+			{Name: "b", DataType: DataTypeNominal, NominalValues: []string{"y", "x"}},
+			{Name: "d", DataType: DataTypeDate},
+		}})
+
+		var msgs []string
+		for _, c := range diff.Changes {
+			msgs = append(msgs, c.String())
+		}
+		Expect(msgs).To(Equal([]string{
+			"attribute 'a' removed from 0",
+			"attribute 'b' removed labels z",
+			"attribute 'b' reordered labels",
+			"attribute 'c' changed from STRING to NOMINAL",
+			"attribute 'd' added at 2",
+			"attribute 'c' moved from 2 to 0",
+		}))
+		Expect(diff.Compatibility).To(Equal(Incompatible))
+	})
+
+})
@@ -0,0 +1,351 @@
+package arff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// DiscretizeMethod is a discretisation method
+type DiscretizeMethod uint8
+
+const (
+	// EqualWidth splits the value range into bins of equal width
+	EqualWidth DiscretizeMethod = iota
+	// EqualFrequency splits values into bins of (roughly) equal weight
+	EqualFrequency
+	// MDL splits values recursively by class entropy and stops according
+	// to Fayyad and Irani's minimum description length criterion. The
+	// class attribute must be nominal.
+	MDL
+)
+
+// Discretize is a Filter which converts numeric attributes into nominal
+// ones. Values are replaced by labels of the intervals they fall into,
+// e.g. '(-inf-5.3]', '(5.3-7.1]' and '(7.1-inf)'. Like Rescale, it is
+// fitted on one dataset and may then be applied to others.
+type Discretize struct {
+	// Method is the discretisation method. Default: EqualWidth
+	Method DiscretizeMethod
+
+	// Attributes are the names of the attributes to discretise.
+	// Default: all numeric attributes
+	Attributes []string
+
+	// Bins is the number of bins for unsupervised methods. Default: 10
+	Bins int
+
+	// Cuts maps attribute names to ascending cut points. They are
+	// computed by Fit or may be supplied.
+	Cuts map[string][]float64
+
+	index  []int
+	cuts   [][]float64
+	labels [][]string
+}
+
+// Fit computes cut points for attributes of rel from all remaining rows
+// of it, replacing existing ones. Row weights are taken into account and
+// missing values are ignored.
+func (f *Discretize) Fit(rel *Relation, it Iterator) error {
+	index, err := f.attributes(rel)
+	if err != nil {
+		return err
+	}
+
+	classPos := -1
+	if f.Method == MDL {
+		if classPos = rel.ClassIndex(); classPos < 0 {
+			return fmt.Errorf("%s '%s'", errUnknownAttr.Error(), rel.Class)
+		} else if attr := rel.Attributes[classPos]; attr.DataType != DataTypeNominal {
+			return fmt.Errorf("%s '%s'", errNonNominalAttr.Error(), attr.Name)
+		}
+	}
+
+	values := make([][]classifiedValue, len(index))
+	for it.Next() {
+		row := it.Row()
+
+		var class string
+		if classPos > -1 {
+			if row.Values[classPos] == nil {
+				continue
+			}
+			class = fmt.Sprint(row.Values[classPos])
+		}
+
+		for i, pos := range index {
+			if v := row.Values[pos]; v != nil {
+				x, ok := numericValue(v)
+				if !ok {
+					return fmt.Errorf("value '%v' is not numeric", v)
+				}
+				values[i] = append(values[i], classifiedValue{value: x, weight: row.weight(), class: class})
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	bins := f.Bins
+	if bins < 1 {
+		bins = 10
+	}
+
+	f.Cuts = make(map[string][]float64, len(index))
+	for i, pos := range index {
+		vals := values[i]
+		sort.SliceStable(vals, func(i, j int) bool { return vals[i].value < vals[j].value })
+
+		var cuts []float64
+		switch f.Method {
+		case EqualFrequency:
+			cuts = equalFrequencyCuts(vals, bins)
+		case MDL:
+			cuts = mdlCuts(vals)
+		default:
+			cuts = equalWidthCuts(vals, bins)
+		}
+		f.Cuts[rel.Attributes[pos].Name] = cuts
+	}
+	return nil
+}
+
+// Init implements Filter
+func (f *Discretize) Init(in *Relation) (*Relation, error) {
+	index, err := f.attributes(in)
+	if err != nil {
+		return nil, err
+	}
+
+	out := *in
+	out.Attributes = append([]Attribute(nil), in.Attributes...)
+
+	f.index, f.cuts, f.labels = index, f.cuts[:0], f.labels[:0]
+	for _, pos := range index {
+		attr := &out.Attributes[pos]
+		cuts, ok := f.Cuts[attr.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s '%s'", errUnfittedAttr.Error(), attr.Name)
+		}
+
+		labels := intervalLabels(cuts)
+		attr.DataType, attr.NominalValues = DataTypeNominal, labels
+		f.cuts = append(f.cuts, cuts)
+		f.labels = append(f.labels, labels)
+	}
+	return &out, nil
+}
+
+// Apply implements Filter
+func (f *Discretize) Apply(row *DataRow) (*DataRow, error) {
+	for i, pos := range f.index {
+		v := row.Values[pos]
+		if v == nil {
+			continue
+		}
+
+		x, ok := numericValue(v)
+		if !ok {
+			return nil, fmt.Errorf("value '%v' is not numeric", v)
+		}
+		row.Values[pos] = f.labels[i][sort.SearchFloat64s(f.cuts[i], x)]
+	}
+	return row, nil
+}
+
+func (f *Discretize) attributes(rel *Relation) ([]int, error) {
+	var index []int
+	if len(f.Attributes) == 0 {
+		classPos := -1
+		if f.Method == MDL {
+			classPos = rel.ClassIndex()
+		}
+		for pos, attr := range rel.Attributes {
+			if attr.DataType == DataTypeNumeric && pos != classPos {
+				index = append(index, pos)
+			}
+		}
+		return index, nil
+	}
+
+	for _, name := range f.Attributes {
+		pos := rel.AttributeIndex(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+		if rel.Attributes[pos].DataType != DataTypeNumeric {
+			return nil, fmt.Errorf("%s '%s'", errNonNumericAttr.Error(), name)
+		}
+		index = append(index, pos)
+	}
+	return index, nil
+}
+
+// --------------------------------------------------------------------
+
+type classifiedValue struct {
+	value, weight float64
+	class         string
+}
+
+// intervalLabels returns the labels of the intervals delimited by cuts
+func intervalLabels(cuts []float64) []string {
+	if len(cuts) == 0 {
+		return []string{"All"}
+	}
+
+	labels := make([]string, 0, len(cuts)+1)
+	lower := "-inf"
+	for _, c := range cuts {
+		upper := formatCut(c)
+		labels = append(labels, "("+lower+"-"+upper+"]")
+		lower = upper
+	}
+	return append(labels, "("+lower+"-inf)")
+}
+
+func formatCut(c float64) string {
+	return strconv.FormatFloat(math.Round(c*1e6)/1e6, 'f', -1, 64)
+}
+
+func equalWidthCuts(vals []classifiedValue, bins int) []float64 {
+	if len(vals) == 0 {
+		return nil
+	}
+
+	min, max := vals[0].value, vals[len(vals)-1].value
+	if min == max {
+		return nil
+	}
+
+	width := (max - min) / float64(bins)
+	cuts := make([]float64, 0, bins-1)
+	for i := 1; i < bins; i++ {
+		cuts = append(cuts, min+float64(i)*width)
+	}
+	return cuts
+}
+
+func equalFrequencyCuts(vals []classifiedValue, bins int) []float64 {
+	var total float64
+	for _, v := range vals {
+		total += v.weight
+	}
+
+	var cuts []float64
+	per := total / float64(bins)
+	next, cum := per, 0.0
+	for i := 0; i < len(vals)-1; i++ {
+		cum += vals[i].weight
+		if vals[i].value == vals[i+1].value || cum < next {
+			continue
+		}
+
+		cuts = append(cuts, (vals[i].value+vals[i+1].value)/2)
+		for next <= cum {
+			next += per
+		}
+	}
+	return cuts
+}
+
+// mdlCuts splits sorted values recursively by minimum class entropy,
+// accepting splits which satisfy the MDL criterion
+func mdlCuts(vals []classifiedValue) []float64 {
+	if len(vals) < 2 {
+		return nil
+	}
+
+	all := newClassCounts(vals)
+	left := make(classCounts)
+
+	best, bestEntropy := -1, math.Inf(1)
+	var bestLeft, bestRight classCounts
+	for i := 0; i < len(vals)-1; i++ {
+		left.add(vals[i].class, vals[i].weight)
+		if vals[i].value == vals[i+1].value {
+			continue
+		}
+
+		right := all.minus(left)
+		lw, rw := left.total(), right.total()
+		if e := (lw*left.entropy() + rw*right.entropy()) / (lw + rw); e < bestEntropy {
+			best, bestEntropy = i, e
+			bestLeft, bestRight = left.clone(), right
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+
+	// Fayyad & Irani's MDL stopping criterion
+	n := all.total()
+	e, e1, e2 := all.entropy(), bestLeft.entropy(), bestRight.entropy()
+	k, k1, k2 := float64(len(all)), float64(len(bestLeft)), float64(len(bestRight))
+	gain := e - bestEntropy
+	delta := math.Log2(math.Pow(3, k)-2) - (k*e - k1*e1 - k2*e2)
+	if gain <= (math.Log2(n-1)+delta)/n {
+		return nil
+	}
+
+	cut := (vals[best].value + vals[best+1].value) / 2
+	cuts := mdlCuts(vals[:best+1])
+	cuts = append(cuts, cut)
+	return append(cuts, mdlCuts(vals[best+1:])...)
+}
+
+// classCounts are weighted class frequencies
+type classCounts map[string]float64
+
+func newClassCounts(vals []classifiedValue) classCounts {
+	c := make(classCounts)
+	for _, v := range vals {
+		c.add(v.class, v.weight)
+	}
+	return c
+}
+
+func (c classCounts) add(class string, weight float64) { c[class] += weight }
+
+func (c classCounts) total() float64 {
+	var sum float64
+	for _, w := range c {
+		sum += w
+	}
+	return sum
+}
+
+func (c classCounts) entropy() float64 {
+	total := c.total()
+
+	var e float64
+	for _, w := range c {
+		if w > 0 {
+			p := w / total
+			e -= p * math.Log2(p)
+		}
+	}
+	return e
+}
+
+func (c classCounts) clone() classCounts {
+	d := make(classCounts, len(c))
+	for k, w := range c {
+		d[k] = w
+	}
+	return d
+}
+
+// minus returns the counts of c without those of d, omitting empty classes
+func (c classCounts) minus(d classCounts) classCounts {
+	r := make(classCounts, len(c))
+	for k, w := range c {
+		if w -= d[k]; w > 0 {
+			r[k] = w
+		}
+	}
+	return r
+}
@@ -0,0 +1,130 @@
+package arff
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Discretize", func() {
+	var data, threeClasses *Dataset
+
+	BeforeEach(func() {
+		data = &Dataset{Relation: Relation{Name: "test", Attributes: []Attribute{
+			{Name: "x", DataType: DataTypeNumeric},
+			{Name: "y", DataType: DataTypeNumeric},
+			{Name: "c", DataType: DataTypeNominal, NominalValues: []string{"a", "b"}},
+		}}}
+		for i, x := range []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10} {
+			class := "a"
+			if i > 5 {
+				class = "b"
+			}
+			data.Rows = append(data.Rows, DataRow{Values: []interface{}{x, 5.0, class}})
+		}
+
+		threeClasses = &Dataset{Relation: Relation{Name: "test", Attributes: []Attribute{
+			{Name: "x", DataType: DataTypeNumeric},
+			{Name: "c", DataType: DataTypeNominal, NominalValues: []string{"a", "b", "c"}},
+		}}}
+		for i := 0; i < 15; i++ {
+			class := []string{"a", "b", "c"}[i/5]
+			threeClasses.Rows = append(threeClasses.Rows, DataRow{Values: []interface{}{float64(i + 1), class}})
+		}
+	})
+
+	It("should discretise by equal width", func() {
+		f := &Discretize{Bins: 3, Attributes: []string{"x"}}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Cuts).To(Equal(map[string][]float64{"x": {4, 7}}))
+
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes[0]).To(Equal(Attribute{
+			Name:          "x",
+			DataType:      DataTypeNominal,
+			NominalValues: []string{"(-inf-4]", "(4-7]", "(7-inf)"},
+		}))
+		Expect(out.Attributes[1]).To(Equal(data.Attributes[1]))
+		Expect(data.Attributes[0].DataType).To(Equal(DataTypeNumeric))
+
+		var labels []interface{}
+		for _, row := range out.Rows {
+			labels = append(labels, row.Values[0])
+		}
+		Expect(labels).To(Equal([]interface{}{
+			"(-inf-4]", "(-inf-4]", "(-inf-4]", "(-inf-4]",
+			"(4-7]", "(4-7]", "(4-7]",
+			"(7-inf)", "(7-inf)", "(7-inf)",
+		}))
+	})
+
+	It("should discretise by equal frequency", func() {
+		f := &Discretize{Method: EqualFrequency, Bins: 3}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Cuts).To(Equal(map[string][]float64{
+			"x": {4.5, 7.5},
+			"y": nil,
+		}))
+
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes[1].NominalValues).To(Equal([]string{"All"}))
+		Expect(out.Rows[0].Values).To(Equal([]interface{}{"(-inf-4.5]", "All", "a"}))
+
+		data.Rows[9].Weight = 5
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Cuts).To(HaveKeyWithValue("x", []float64{5.5}))
+	})
+
+	It("should discretise supervised", func() {
+		f := &Discretize{Method: MDL}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Cuts).To(Equal(map[string][]float64{
+			"x": {6.5},
+			"y": nil,
+		}))
+
+		f = &Discretize{Method: MDL}
+		Expect(f.Fit(&threeClasses.Relation, threeClasses.Iterator())).To(Succeed())
+		Expect(f.Cuts).To(Equal(map[string][]float64{"x": {5.5, 10.5}}))
+
+		weather, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Fit(&weather.Relation, weather.Iterator())).To(Succeed())
+		Expect(f.Cuts).To(Equal(map[string][]float64{"temperature": nil, "humidity": nil}))
+
+		data.Attributes[2].DataType = DataTypeString
+		Expect((&Discretize{Method: MDL}).Fit(&data.Relation, data.Iterator())).To(MatchError("non-nominal attribute 'c'"))
+	})
+
+	It("should validate attributes", func() {
+		Expect((&Discretize{Attributes: []string{"z"}}).Fit(&data.Relation, data.Iterator())).To(MatchError("unknown attribute 'z'"))
+		Expect((&Discretize{Attributes: []string{"c"}}).Fit(&data.Relation, data.Iterator())).To(MatchError("non-numeric attribute 'c'"))
+
+		_, err := data.Filter(&Discretize{Attributes: []string{"x"}})
+		Expect(err).To(MatchError("unfitted attribute 'x'"))
+	})
+
+	It("should stream between reader and writer", func() {
+		f := &Discretize{Bins: 2, Attributes: []string{"temperature"}}
+		weather, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Fit(&weather.Relation, weather.Iterator())).To(Succeed())
+
+		r, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		buf := new(bytes.Buffer)
+		Expect(ApplyFilters(buf, r, f)).To(Succeed())
+
+		out, err := readDataset(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes[1].NominalValues).To(Equal([]string{"(-inf-74.5]", "(74.5-inf)"}))
+		Expect(out.Rows).To(HaveLen(14))
+		Expect(out.Rows[0].Values).To(Equal([]interface{}{"sunny", "(74.5-inf)", 85.0, "FALSE", "no"}))
+	})
+
+})
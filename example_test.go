@@ -38,6 +38,29 @@ func ExampleReader() {
 	// rainy 71 91 TRUE no
 }
 
+func ExampleReader_All() {
+	data, err := arff.Open("./testdata/weather.arff")
+	if err != nil {
+		panic("failed to open file: " + err.Error())
+	}
+	defer data.Close()
+
+	for row, err := range data.All() {
+		if err != nil {
+			panic("failed to read file: " + err.Error())
+		}
+		if row.Values[0] == "overcast" {
+			fmt.Println(row.Values...)
+		}
+	}
+
+	// Output:
+	// overcast 83 86 FALSE yes
+	// overcast 64 65 TRUE yes
+	// overcast 72 90 TRUE yes
+	// overcast 81 75 FALSE yes
+}
+
 func ExampleWriter() {
 	buf := new(bytes.Buffer)
 	w, err := arff.NewWriter(buf, &arff.Relation{
@@ -0,0 +1,778 @@
+package arff
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expr is an expression compiled against a relation. Expressions consist of
+// attribute names, literals and operators, e.g.
+//
+//	outlook = 'sunny' and temperature > 75 and humidity is not missing
+//
+// Supported are numeric, string and date values and the operators:
+//
+//	or, and, not                 logical, with missing as unknown
+//	=, !=, <>, <, <=, >, >=      comparison, missing if either side is
+//	is missing, is not missing   check for missing values
+//	in ('a', 'b')                membership
+//	+, -, *, /                   arithmetic, division by zero is missing
+//
+// and functions:
+//
+//	log, exp, sqrt, abs          numeric, invalid results are missing
+//	round, floor, ceil           numeric rounding
+//	year, month, day, hour       date parts
+//	weekday                      day of the week of a date, 0 is Sunday
+//	length                       number of characters of a string
+//	lower, upper                 string case conversion
+//
+// Attribute names which are no valid identifiers or collide with keywords
+// must be double-quoted, string literals are single-quoted. Nominal values
+// are strings and dates compare with ISO8601 string literals. Type errors
+// and unknown nominal labels are reported by Compile.
+type Expr struct {
+	src  string
+	node *exprNode
+}
+
+// Compile parses src and binds it to the attributes of rel
+func Compile(rel *Relation, src string) (*Expr, error) {
+	p := &exprParser{rel: rel, src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf(p.tok.pos, "unexpected %s", p.tok)
+	}
+	return &Expr{src: src, node: node}, nil
+}
+
+// String returns the expression source
+func (e *Expr) String() string { return e.src }
+
+// IsBool returns true if the expression evaluates to a boolean
+func (e *Expr) IsBool() bool { return e.node.typ == exprBool }
+
+// Eval evaluates the expression for row. It returns a float64, string,
+// time.Time, bool or nil if the result is missing.
+func (e *Expr) Eval(row *DataRow) interface{} {
+	return e.node.eval(row)
+}
+
+// Match returns true if the expression evaluates to true
+func (e *Expr) Match(row *DataRow) bool {
+	b, _ := e.node.eval(row).(bool)
+	return b
+}
+
+// SelectRows is a Filter which retains rows matching a boolean expression,
+// see Expr
+type SelectRows struct {
+	// Expr is the expression source
+	Expr string
+
+	expr *Expr
+}
+
+// Init implements Filter
+func (f *SelectRows) Init(in *Relation) (*Relation, error) {
+	expr, err := Compile(in, f.Expr)
+	if err != nil {
+		return nil, err
+	}
+	if !expr.IsBool() {
+		return nil, fmt.Errorf("%s: %s is not boolean", errBadExpr.Error(), expr.node.typ)
+	}
+	f.expr = expr
+	return in, nil
+}
+
+// Apply implements Filter
+func (f *SelectRows) Apply(row *DataRow) (*DataRow, error) {
+	if !f.expr.Match(row) {
+		return nil, nil
+	}
+	return row, nil
+}
+
+// --------------------------------------------------------------------
+
+type exprType uint8
+
+const (
+	exprNumeric exprType = iota
+	exprString
+	exprDate
+	exprBool
+)
+
+func (t exprType) String() string {
+	switch t {
+	case exprNumeric:
+		return "numeric"
+	case exprString:
+		return "string"
+	case exprDate:
+		return "date"
+	}
+	return "boolean"
+}
+
+type exprNode struct {
+	typ  exprType
+	eval func(*DataRow) interface{}
+
+	attr   *Attribute  // referenced attribute
+	lit    interface{} // literal value
+	isLit  bool
+	litPos int
+}
+
+func literalNode(typ exprType, v interface{}, pos int) *exprNode {
+	return &exprNode{typ: typ, eval: func(*DataRow) interface{} { return v }, lit: v, isLit: true, litPos: pos}
+}
+
+func attributeNode(attr *Attribute, pos int) *exprNode {
+	n := &exprNode{attr: attr}
+	switch attr.DataType {
+	case DataTypeNumeric:
+		n.typ = exprNumeric
+		n.eval = func(row *DataRow) interface{} {
+			if f, ok := numericValue(row.Values[pos]); ok {
+				return f
+			}
+			return nil
+		}
+	case DataTypeDate:
+		n.typ = exprDate
+		n.eval = func(row *DataRow) interface{} {
+			if t, ok := row.Values[pos].(time.Time); ok {
+				return t
+			}
+			return nil
+		}
+	default:
+		n.typ = exprString
+		n.eval = func(row *DataRow) interface{} {
+			if v := row.Values[pos]; v != nil {
+				return fmt.Sprint(v)
+			}
+			return nil
+		}
+	}
+	return n
+}
+
+type tokenKind uint8
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokKeyword
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return "'" + t.text + "'"
+}
+
+var exprKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "is": true, "missing": true,
+	"in": true, "true": true, "false": true,
+}
+
+type exprParser struct {
+	rel *Relation
+	src string
+	off int
+	tok token
+}
+
+func (p *exprParser) errorf(pos int, format string, args ...interface{}) error {
+	return fmt.Errorf("%s at %d: %s", errBadExpr.Error(), pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) is(kind tokenKind, text string) bool {
+	return p.tok.kind == kind && p.tok.text == text
+}
+
+// next advances to the next token
+func (p *exprParser) next() error {
+	for p.off < len(p.src) && (p.src[p.off] == ' ' || p.src[p.off] == '\t' || p.src[p.off] == '\n' || p.src[p.off] == '\r') {
+		p.off++
+	}
+
+	start := p.off
+	if start == len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+
+	c := p.src[start]
+	switch {
+	case c == '\'' || c == '"':
+		s, n, ok := scanQuoted(p.src[start:], c)
+		if !ok {
+			return p.errorf(start, "unterminated quote")
+		}
+		p.off += n
+		if c == '"' {
+			p.tok = token{kind: tokIdent, text: s, pos: start}
+		} else {
+			p.tok = token{kind: tokString, text: s, pos: start}
+		}
+	case c >= '0' && c <= '9' || c == '.' && start+1 < len(p.src) && p.src[start+1] >= '0' && p.src[start+1] <= '9':
+		end := start
+		for end < len(p.src) && (isDigit(p.src[end]) || p.src[end] == '.' ||
+			(p.src[end] == 'e' || p.src[end] == 'E') ||
+			((p.src[end] == '+' || p.src[end] == '-') && (p.src[end-1] == 'e' || p.src[end-1] == 'E'))) {
+			end++
+		}
+		p.off = end
+		p.tok = token{kind: tokNumber, text: p.src[start:end], pos: start}
+	case strings.ContainsRune("=<>!+-*/(),", rune(c)):
+		op := p.src[start : start+1]
+		if start+1 < len(p.src) {
+			switch two := p.src[start : start+2]; two {
+			case "!=", "<>", "<=", ">=":
+				op = two
+			}
+		}
+		if op == "!" {
+			return p.errorf(start, "unexpected '!'")
+		}
+		p.off += len(op)
+		p.tok = token{kind: tokOp, text: op, pos: start}
+	default:
+		end := start
+		for end < len(p.src) {
+			r, size := utf8.DecodeRuneInString(p.src[end:])
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' {
+				break
+			}
+			end += size
+		}
+		if end == start {
+			r, _ := utf8.DecodeRuneInString(p.src[start:])
+			return p.errorf(start, "unexpected '%c'", r)
+		}
+		p.off = end
+
+		text := p.src[start:end]
+		if lower := strings.ToLower(text); exprKeywords[lower] {
+			p.tok = token{kind: tokKeyword, text: lower, pos: start}
+		} else {
+			p.tok = token{kind: tokIdent, text: text, pos: start}
+		}
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (*exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokKeyword, "or") {
+		pos := p.tok.pos
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = p.logical(pos, "or", left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (*exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokKeyword, "and") {
+		pos := p.tok.pos
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = p.logical(pos, "and", left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (*exprNode, error) {
+	if !p.is(tokKeyword, "not") {
+		return p.parseComparison()
+	}
+
+	pos := p.tok.pos
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	operand, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	if operand.typ != exprBool {
+		return nil, p.errorf(pos, "cannot negate %s", operand.typ)
+	}
+	return &exprNode{typ: exprBool, eval: func(row *DataRow) interface{} {
+		if b, ok := operand.eval(row).(bool); ok {
+			return !b
+		}
+		return nil
+	}}, nil
+}
+
+func (p *exprParser) parseComparison() (*exprNode, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	pos := p.tok.pos
+	switch {
+	case p.is(tokKeyword, "is"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		negate := p.is(tokKeyword, "not")
+		if negate {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+		if !p.is(tokKeyword, "missing") {
+			return nil, p.errorf(p.tok.pos, "expected 'missing', found %s", p.tok)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return &exprNode{typ: exprBool, eval: func(row *DataRow) interface{} {
+			return (left.eval(row) == nil) != negate
+		}}, nil
+
+	case p.is(tokKeyword, "in"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return p.parseIn(pos, left)
+
+	case p.tok.kind == tokOp:
+		switch op := p.tok.text; op {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			right, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			return p.compare(pos, op, left, right)
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseIn(pos int, left *exprNode) (*exprNode, error) {
+	if !p.is(tokOp, "(") {
+		return nil, p.errorf(p.tok.pos, "expected '(', found %s", p.tok)
+	}
+
+	var items []*exprNode
+	for {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		itemPos := p.tok.pos
+		item, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if item, err = p.coerce(left, item); err != nil {
+			return nil, err
+		}
+		if item.typ != left.typ {
+			return nil, p.errorf(itemPos, "cannot compare %s with %s", left.typ, item.typ)
+		}
+		items = append(items, item)
+
+		if p.is(tokOp, ")") {
+			break
+		} else if !p.is(tokOp, ",") {
+			return nil, p.errorf(p.tok.pos, "expected ',' or ')', found %s", p.tok)
+		}
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	return &exprNode{typ: exprBool, eval: func(row *DataRow) interface{} {
+		v := left.eval(row)
+		if v == nil {
+			return nil
+		}
+		for _, item := range items {
+			if w := item.eval(row); w != nil && compareValues(v, w) == 0 {
+				return true
+			}
+		}
+		return false
+	}}, nil
+}
+
+func (p *exprParser) parseSum() (*exprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokOp, "+") || p.is(tokOp, "-") {
+		op, pos := p.tok.text, p.tok.pos
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = p.arithmetic(pos, op, left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseProduct() (*exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokOp, "*") || p.is(tokOp, "/") {
+		op, pos := p.tok.text, p.tok.pos
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = p.arithmetic(pos, op, left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (*exprNode, error) {
+	if !p.is(tokOp, "-") {
+		return p.parsePrimary()
+	}
+
+	pos := p.tok.pos
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return p.arithmetic(pos, "-", literalNode(exprNumeric, 0.0, pos), operand)
+}
+
+func (p *exprParser) parsePrimary() (*exprNode, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok.pos, "invalid number '%s'", tok.text)
+		}
+		return literalNode(exprNumeric, f, tok.pos), p.next()
+
+	case tok.kind == tokString:
+		return literalNode(exprString, tok.text, tok.pos), p.next()
+
+	case p.is(tokKeyword, "true"), p.is(tokKeyword, "false"):
+		return literalNode(exprBool, tok.text == "true", tok.pos), p.next()
+
+	case tok.kind == tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.is(tokOp, "(") && p.src[tok.pos] != '"' {
+			return p.parseCall(tok)
+		}
+
+		pos := p.rel.AttributeIndex(tok.text)
+		if pos < 0 {
+			return nil, p.errorf(tok.pos, "%s '%s'", errUnknownAttr.Error(), tok.text)
+		}
+		return attributeNode(&p.rel.Attributes[pos], pos), nil
+
+	case p.is(tokOp, "("):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.is(tokOp, ")") {
+			return nil, p.errorf(p.tok.pos, "expected ')', found %s", p.tok)
+		}
+		return node, p.next()
+	}
+	return nil, p.errorf(tok.pos, "unexpected %s", tok)
+}
+
+func (p *exprParser) parseCall(name token) (*exprNode, error) {
+	fn, ok := exprFuncs[strings.ToLower(name.text)]
+	if !ok {
+		return nil, p.errorf(name.pos, "unknown function '%s'", name.text)
+	}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	arg, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.is(tokOp, ")") {
+		return nil, p.errorf(p.tok.pos, "expected ')', found %s", p.tok)
+	}
+	if arg.typ != fn.arg {
+		return nil, p.errorf(name.pos, "cannot apply '%s' to %s", name.text, arg.typ)
+	}
+
+	return &exprNode{typ: fn.typ, eval: func(row *DataRow) interface{} {
+		if v := arg.eval(row); v != nil {
+			return fn.eval(v)
+		}
+		return nil
+	}}, p.next()
+}
+
+func (p *exprParser) logical(pos int, op string, left, right *exprNode) (*exprNode, error) {
+	if left.typ != exprBool || right.typ != exprBool {
+		return nil, p.errorf(pos, "cannot combine %s and %s with '%s'", left.typ, right.typ, op)
+	}
+
+	// Kleene logic: missing values are unknown
+	and := op == "and"
+	return &exprNode{typ: exprBool, eval: func(row *DataRow) interface{} {
+		a, aok := left.eval(row).(bool)
+		if aok && a != and {
+			return a
+		}
+		b, bok := right.eval(row).(bool)
+		if bok && b != and {
+			return b
+		}
+		if aok && bok {
+			return and
+		}
+		return nil
+	}}, nil
+}
+
+func (p *exprParser) compare(pos int, op string, left, right *exprNode) (*exprNode, error) {
+	right, err := p.coerce(left, right)
+	if err != nil {
+		return nil, err
+	}
+	if left, err = p.coerce(right, left); err != nil {
+		return nil, err
+	}
+	if left.typ != right.typ {
+		return nil, p.errorf(pos, "cannot compare %s with %s", left.typ, right.typ)
+	}
+	if left.typ == exprBool && op != "=" && op != "!=" && op != "<>" {
+		return nil, p.errorf(pos, "cannot apply '%s' to %s and %s", op, left.typ, right.typ)
+	}
+
+	return &exprNode{typ: exprBool, eval: func(row *DataRow) interface{} {
+		a, b := left.eval(row), right.eval(row)
+		if a == nil || b == nil {
+			return nil
+		}
+
+		var n int
+		if ba, ok := a.(bool); ok {
+			if bb := b.(bool); ba != bb {
+				n = 1
+			}
+		} else {
+			n = compareValues(a, b)
+		}
+
+		switch op {
+		case "=":
+			return n == 0
+		case "!=", "<>":
+			return n != 0
+		case "<":
+			return n < 0
+		case "<=":
+			return n <= 0
+		case ">":
+			return n > 0
+		}
+		return n >= 0
+	}}, nil
+}
+
+// coerce converts string literals compared with other, checking nominal
+// labels and parsing dates
+func (p *exprParser) coerce(other, node *exprNode) (*exprNode, error) {
+	if !node.isLit || node.typ != exprString {
+		return node, nil
+	}
+	s := node.lit.(string)
+
+	switch other.typ {
+	case exprDate:
+		t, err := time.ParseInLocation(iso8691DateFormat, s, utc)
+		if err != nil {
+			if t, err = time.ParseInLocation("2006-01-02", s, utc); err != nil {
+				return nil, p.errorf(node.litPos, "value '%s' is not an ISO8601 date", s)
+			}
+		}
+		return literalNode(exprDate, t, node.litPos), nil
+	case exprString:
+		if attr := other.attr; attr != nil && attr.DataType == DataTypeNominal && indexOf(attr.NominalValues, s) < 0 {
+			return nil, p.errorf(node.litPos, "value '%s' is not a label of '%s'", s, attr.Name)
+		}
+	}
+	return node, nil
+}
+
+func (p *exprParser) arithmetic(pos int, op string, left, right *exprNode) (*exprNode, error) {
+	if left.typ != exprNumeric || right.typ != exprNumeric {
+		return nil, p.errorf(pos, "cannot apply '%s' to %s and %s", op, left.typ, right.typ)
+	}
+
+	var fn func(a, b float64) float64
+	switch op {
+	case "+":
+		fn = func(a, b float64) float64 { return a + b }
+	case "-":
+		fn = func(a, b float64) float64 { return a - b }
+	case "*":
+		fn = func(a, b float64) float64 { return a * b }
+	case "/":
+		fn = func(a, b float64) float64 { return a / b }
+	}
+
+	return &exprNode{typ: exprNumeric, eval: func(row *DataRow) interface{} {
+		a, aok := left.eval(row).(float64)
+		b, bok := right.eval(row).(float64)
+		if !aok || !bok {
+			return nil
+		}
+		if f := fn(a, b); !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return f
+		}
+		return nil
+	}}, nil
+}
+
+type exprFunc struct {
+	arg, typ exprType
+	eval     func(interface{}) interface{}
+}
+
+func numericFunc(fn func(float64) float64) exprFunc {
+	return exprFunc{arg: exprNumeric, typ: exprNumeric, eval: func(v interface{}) interface{} {
+		if f := fn(v.(float64)); !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return f
+		}
+		return nil
+	}}
+}
+
+func dateFunc(fn func(time.Time) int) exprFunc {
+	return exprFunc{arg: exprDate, typ: exprNumeric, eval: func(v interface{}) interface{} {
+		return float64(fn(v.(time.Time)))
+	}}
+}
+
+func stringFunc(fn func(string) string) exprFunc {
+	return exprFunc{arg: exprString, typ: exprString, eval: func(v interface{}) interface{} {
+		return fn(v.(string))
+	}}
+}
+
+var exprFuncs = map[string]exprFunc{
+	"log":     numericFunc(math.Log),
+	"exp":     numericFunc(math.Exp),
+	"sqrt":    numericFunc(math.Sqrt),
+	"abs":     numericFunc(math.Abs),
+	"round":   numericFunc(math.Round),
+	"floor":   numericFunc(math.Floor),
+	"ceil":    numericFunc(math.Ceil),
+	"year":    dateFunc(time.Time.Year),
+	"month":   dateFunc(func(t time.Time) int { return int(t.Month()) }),
+	"day":     dateFunc(time.Time.Day),
+	"hour":    dateFunc(time.Time.Hour),
+	"weekday": dateFunc(func(t time.Time) int { return int(t.Weekday()) }),
+	"length": {arg: exprString, typ: exprNumeric, eval: func(v interface{}) interface{} {
+		return float64(utf8.RuneCountInString(v.(string)))
+	}},
+	"lower": stringFunc(strings.ToLower),
+	"upper": stringFunc(strings.ToUpper),
+}
+
+// scanQuoted returns the unescaped content of the quoted string at the
+// beginning of s and the number of bytes consumed
+func scanQuoted(s string, quote byte) (string, int, bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case quote:
+			return b.String(), i + 1, true
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
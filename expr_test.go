@@ -0,0 +1,119 @@
+package arff
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Expr", func() {
+	rel := &Relation{Name: "test", Attributes: []Attribute{
+		{Name: "outlook", DataType: DataTypeNominal, NominalValues: []string{"sunny", "overcast", "rainy"}},
+		{Name: "temperature", DataType: DataTypeNumeric},
+		{Name: "humidity", DataType: DataTypeNumeric},
+		{Name: "note", DataType: DataTypeString},
+		{Name: "day", DataType: DataTypeDate},
+		{Name: "and", DataType: DataTypeNumeric},
+	}}
+	row := &DataRow{Values: []interface{}{"sunny", 80.0, nil, "it's warm", time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC), 1.0}}
+
+	DescribeTable("should evaluate",
+		func(src string, exp interface{}) {
+			expr, err := Compile(rel, src)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expr.String()).To(Equal(src))
+			if exp == nil {
+				Expect(expr.Eval(row)).To(BeNil())
+			} else {
+				Expect(expr.Eval(row)).To(Equal(exp))
+			}
+		},
+
+		Entry("comparison", "temperature > 75", true),
+		Entry("nominal", "outlook = 'sunny'", true),
+		Entry("not equal", "outlook <> 'sunny'", false),
+		Entry("string escapes", `note = 'it\'s warm'`, true),
+		Entry("missing", "humidity is missing", true),
+		Entry("not missing", "humidity IS NOT MISSING", false),
+		Entry("unknown comparison", "humidity > 50", nil),
+		Entry("kleene and", "humidity > 50 and false", false),
+		Entry("kleene or", "humidity > 50 or true", true),
+		Entry("kleene unknown", "humidity > 50 or false", nil),
+		Entry("not", "not (temperature < 75)", true),
+		Entry("boolean equality", "(temperature > 75) = true", true),
+		Entry("precedence", "1 + 2 * -3", -5.0),
+		Entry("parentheses", "(1 + 2) * 3 / 2", 4.5),
+		Entry("missing arithmetic", "temperature - humidity", nil),
+		Entry("division by zero", "temperature / 0", nil),
+		Entry("membership", "outlook in ('rainy', 'sunny')", true),
+		Entry("numeric membership", "temperature in (1, 2e1)", false),
+		Entry("dates", "day >= '2018-06-01' and day < '2018-06-01T13:00:00'", true),
+		Entry("quoted names", `"and" = 1`, true),
+		Entry("log", "log(temperature / 80)", 0.0),
+		Entry("invalid log", "log(0)", nil),
+		Entry("rounding", "round(2.5) + floor(-1.5) + ceil(0.2) + abs(-1)", 3.0),
+		Entry("date parts", "year(day) * 10000 + month(day) * 100 + day(day)", 20180601.0),
+		Entry("weekday", "weekday(day) + hour(day)", 17.0),
+		Entry("length", "length(note)", 9.0),
+		Entry("case", "upper(note) = 'IT\\'S WARM' and lower('A') = 'a'", true),
+		Entry("full example", "outlook = 'sunny' and temperature > 75 and humidity is not missing", false),
+	)
+
+	DescribeTable("should report errors",
+		func(src string, msg string) {
+			_, err := Compile(rel, src)
+			Expect(err).To(MatchError(msg))
+		},
+
+		Entry("unknown attribute", "wind = 1", "bad expression at 1: unknown attribute 'wind'"),
+		Entry("unknown label", "outlook = 'foggy'", "bad expression at 11: value 'foggy' is not a label of 'outlook'"),
+		Entry("type mismatch", "outlook > 5", "bad expression at 9: cannot compare string with numeric"),
+		Entry("membership type mismatch", "temperature in (1, 'hot')", "bad expression at 20: cannot compare numeric with string"),
+		Entry("membership label", "outlook in ('sunny', 'foggy')", "bad expression at 22: value 'foggy' is not a label of 'outlook'"),
+		Entry("boolean ordering", "true < false", "bad expression at 6: cannot apply '<' to boolean and boolean"),
+		Entry("boolean comparison ordering", "(temperature > 1) >= true", "bad expression at 19: cannot apply '>=' to boolean and boolean"),
+		Entry("arithmetic", "note + 1", "bad expression at 6: cannot apply '+' to string and numeric"),
+		Entry("logical", "temperature and true", "bad expression at 13: cannot combine numeric and boolean with 'and'"),
+		Entry("negation", "not temperature", "bad expression at 1: cannot negate numeric"),
+		Entry("bad date", "day > 'yesterday'", "bad expression at 7: value 'yesterday' is not an ISO8601 date"),
+		Entry("unterminated", "note = 'x", "bad expression at 8: unterminated quote"),
+		Entry("trailing", "temperature > 1 1", "bad expression at 17: unexpected '1'"),
+		Entry("incomplete", "temperature >", "bad expression at 14: unexpected end of expression"),
+		Entry("is", "temperature is 1", "bad expression at 16: expected 'missing', found '1'"),
+		Entry("unknown function", "foo(1)", "bad expression at 1: unknown function 'foo'"),
+		Entry("function argument", "year(temperature)", "bad expression at 1: cannot apply 'year' to numeric"),
+		Entry("character", "temperature ? 1", "bad expression at 13: unexpected '?'"),
+	)
+
+})
+
+var _ = Describe("SelectRows", func() {
+
+	It("should filter rows", func() {
+		r, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		buf := new(bytes.Buffer)
+		Expect(ApplyFilters(buf, r, &SelectRows{Expr: "outlook = 'sunny' and temperature > 75 and humidity is not missing"})).To(Succeed())
+
+		data, err := readDataset(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{"sunny", 85.0, 85.0, "FALSE", "no"}},
+			{Values: []interface{}{"sunny", 80.0, 90.0, "TRUE", "no"}},
+		}))
+	})
+
+	It("should require boolean expressions", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = data.Filter(&SelectRows{Expr: "temperature + 1"})
+		Expect(err).To(MatchError("bad expression: numeric is not boolean"))
+	})
+
+})
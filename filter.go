@@ -0,0 +1,127 @@
+package arff
+
+import "io"
+
+// Filter transforms relations and their data rows
+type Filter interface {
+	// Init prepares the filter for rows of the input relation and
+	// returns the output relation. It is called once, before Apply.
+	Init(in *Relation) (*Relation, error)
+
+	// Apply transforms a row of the input relation into a row of the
+	// output relation. It may modify row in place and returns nil to drop
+	// the row.
+	Apply(row *DataRow) (*DataRow, error)
+}
+
+// FilterRows initialises filters for rel and returns the output relation
+// together with an iterator over the filtered rows of it.
+func FilterRows(rel *Relation, it Iterator, filters ...Filter) (*Relation, Iterator, error) {
+	for _, f := range filters {
+		out, err := f.Init(rel)
+		if err != nil {
+			return nil, nil, err
+		}
+		rel = out
+	}
+	return rel, &filterIterator{Iterator: it, filters: filters}, nil
+}
+
+// ApplyFilters streams all remaining rows of src through filters and
+// writes the output relation to dst.
+func ApplyFilters(dst io.Writer, src *Reader, filters ...Filter) error {
+	rel, it, err := FilterRows(&src.Relation, src, filters...)
+	if err != nil {
+		return err
+	}
+
+	w, err := NewWriterSize(dst, rel, DefaultBufferSize)
+	if err != nil {
+		return err
+	}
+	if err := w.AppendAll(it); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// Filter returns a new dataset with filters applied to copies of all rows
+func (d *Dataset) Filter(filters ...Filter) (*Dataset, error) {
+	rel, it, err := FilterRows(&d.Relation, &copyIterator{Iterator: d.Iterator()}, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Dataset{Relation: *rel}
+	for it.Next() {
+		out.Rows = append(out.Rows, *it.Row())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// --------------------------------------------------------------------
+
+type filterIterator struct {
+	Iterator
+	filters []Filter
+	row     *DataRow
+	err     error
+}
+
+func (it *filterIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	for it.Iterator.Next() {
+		row, err := applyFilters(it.filters, it.Iterator.Row())
+		if err != nil {
+			it.err, it.row = err, nil
+			return false
+		}
+		if row != nil {
+			it.row = row
+			return true
+		}
+	}
+	it.row = nil
+	return false
+}
+
+func (it *filterIterator) Row() *DataRow { return it.row }
+
+func (it *filterIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Err()
+}
+
+// applyFilters applies filters to row, returning nil if it was dropped
+func applyFilters(filters []Filter, row *DataRow) (*DataRow, error) {
+	for _, f := range filters {
+		var err error
+		if row, err = f.Apply(row); err != nil || row == nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
+
+// copyIterator yields copies of rows, so they can be modified safely
+type copyIterator struct {
+	Iterator
+	row DataRow
+}
+
+func (it *copyIterator) Row() *DataRow {
+	row := it.Iterator.Row()
+	if row == nil {
+		return nil
+	}
+	it.row = DataRow{Values: append([]interface{}(nil), row.Values...), Weight: row.Weight}
+	return &it.row
+}
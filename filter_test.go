@@ -0,0 +1,90 @@
+package arff
+
+import (
+	"bytes"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FilterRows", func() {
+	rel := &Relation{Name: "test", Attributes: []Attribute{{Name: "x", DataType: DataTypeNumeric}}}
+
+	It("should apply filters in order", func() {
+		data := &Dataset{Relation: *rel, Rows: []DataRow{
+			{Values: []interface{}{1.0}},
+			{Values: []interface{}{2.0}},
+			{Values: []interface{}{3.0}},
+		}}
+
+		out, err := data.Filter(dropRows(2.0), scaleRows(10))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Relation).To(Equal(*rel))
+		Expect(out.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{10.0}},
+			{Values: []interface{}{30.0}},
+		}))
+		Expect(data.Rows[0].Values).To(Equal([]interface{}{1.0}))
+	})
+
+	It("should fail on filter errors", func() {
+		data := &Dataset{Relation: *rel, Rows: []DataRow{{Values: []interface{}{1.0}}}}
+
+		_, it, err := FilterRows(&data.Relation, data.Iterator(), failRows{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(it.Next()).To(BeFalse())
+		Expect(it.Row()).To(BeNil())
+		Expect(it.Err()).To(MatchError("failed"))
+	})
+
+	It("should stream from readers to writers", func() {
+		r, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		buf := new(bytes.Buffer)
+		Expect(ApplyFilters(buf, r, dropRows("sunny"))).To(Succeed())
+
+		data, err := readDataset(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Relation).To(Equal(r.Relation))
+		Expect(data.Rows).To(HaveLen(9))
+	})
+
+})
+
+// dropRows drops rows whose first value equals v
+type dropValue struct{ v interface{} }
+
+func dropRows(v interface{}) Filter { return dropValue{v: v} }
+
+func (f dropValue) Init(in *Relation) (*Relation, error) { return in, nil }
+func (f dropValue) Apply(row *DataRow) (*DataRow, error) {
+	if row.Values[0] == f.v {
+		return nil, nil
+	}
+	return row, nil
+}
+
+// scaleRows multiplies the first value
+type scaleRows float64
+
+func (f scaleRows) Init(in *Relation) (*Relation, error) { return in, nil }
+func (f scaleRows) Apply(row *DataRow) (*DataRow, error) {
+	row.Values[0] = row.Values[0].(float64) * float64(f)
+	return row, nil
+}
+
+type failRows struct{}
+
+func (failRows) Init(in *Relation) (*Relation, error) { return in, nil }
+func (failRows) Apply(_ *DataRow) (*DataRow, error)   { return nil, errors.New("failed") }
+
+func readDataset(buf *bytes.Buffer) (*Dataset, error) {
+	r, err := NewReader(buf)
+	if err != nil {
+		return nil, err
+	}
+	return ReadDataset(r)
+}
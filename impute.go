@@ -0,0 +1,259 @@
+package arff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ReplaceMissing is a Filter which replaces missing values, similar to
+// Weka's ReplaceMissingValues. Numeric and date values are replaced by
+// their mean or median, nominal and string values by their mode. The
+// relation is left intact.
+//
+// Replacement values are either supplied as Constants or Values, or
+// computed by Fit in a separate pass over the data.
+type ReplaceMissing struct {
+	// Median replaces numeric and date values by their median instead
+	// of their mean
+	Median bool
+
+	// PerClass computes replacement values separately for each value
+	// of the class attribute, see Relation.ClassIndex. Rows with a
+	// missing class fall back to Values.
+	PerClass bool
+
+	// Constants maps attribute names to constant replacement values,
+	// which take precedence over computed ones
+	Constants map[string]interface{}
+
+	// Values maps attribute names to their replacement values. They are
+	// computed by Fit or may be supplied.
+	Values map[string]interface{}
+
+	// ClassValues maps class values to replacement values by attribute
+	// name. They are computed by Fit if PerClass is set.
+	ClassValues map[string]map[string]interface{}
+
+	names    []string
+	classPos int
+}
+
+// Fit computes replacement values for attributes of rel from all
+// remaining rows of it, replacing existing ones. Row weights are taken
+// into account.
+func (f *ReplaceMissing) Fit(rel *Relation, it Iterator) error {
+	if _, err := f.Init(rel); err != nil {
+		return err
+	}
+
+	global := make([]imputeStats, len(rel.Attributes))
+	perClass := make(map[string][]imputeStats)
+	for it.Next() {
+		row := it.Row()
+		weight := row.weight()
+
+		var stats []imputeStats
+		if f.classPos > -1 && row.Values[f.classPos] != nil {
+			key := fmt.Sprint(row.Values[f.classPos])
+			if stats = perClass[key]; stats == nil {
+				stats = make([]imputeStats, len(rel.Attributes))
+				perClass[key] = stats
+			}
+		}
+
+		for i, v := range row.Values {
+			if v == nil {
+				continue
+			}
+			global[i].add(v, weight)
+			if stats != nil && i != f.classPos {
+				stats[i].add(v, weight)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	f.Values = make(map[string]interface{}, len(rel.Attributes))
+	for i, attr := range rel.Attributes {
+		if _, ok := f.Constants[attr.Name]; ok {
+			continue
+		}
+		if v := global[i].value(&attr, f.Median); v != nil {
+			f.Values[attr.Name] = v
+		}
+	}
+
+	if f.PerClass {
+		f.ClassValues = make(map[string]map[string]interface{}, len(perClass))
+		for key, stats := range perClass {
+			values := make(map[string]interface{})
+			for i, attr := range rel.Attributes {
+				if _, ok := f.Constants[attr.Name]; ok {
+					continue
+				}
+				if v := stats[i].value(&attr, f.Median); v != nil {
+					values[attr.Name] = v
+				}
+			}
+			f.ClassValues[key] = values
+		}
+	}
+	return nil
+}
+
+// Init implements Filter
+func (f *ReplaceMissing) Init(in *Relation) (*Relation, error) {
+	for name := range f.Constants {
+		if in.AttributeIndex(name) < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+	}
+	for name := range f.Values {
+		if in.AttributeIndex(name) < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+	}
+
+	f.names = f.names[:0]
+	for _, attr := range in.Attributes {
+		f.names = append(f.names, attr.Name)
+	}
+
+	f.classPos = -1
+	if f.PerClass {
+		if f.classPos = in.ClassIndex(); f.classPos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), in.Class)
+		}
+	}
+	return in, nil
+}
+
+// Apply implements Filter
+func (f *ReplaceMissing) Apply(row *DataRow) (*DataRow, error) {
+	var classValues map[string]interface{}
+	if f.classPos > -1 && row.Values[f.classPos] != nil {
+		classValues = f.ClassValues[fmt.Sprint(row.Values[f.classPos])]
+	}
+
+	for i, v := range row.Values {
+		if v != nil || i >= len(f.names) {
+			continue
+		}
+
+		name := f.names[i]
+		if v, ok := f.Constants[name]; ok {
+			row.Values[i] = v
+		} else if v, ok := classValues[name]; ok {
+			row.Values[i] = v
+		} else if v, ok := f.Values[name]; ok {
+			row.Values[i] = v
+		}
+	}
+	return row, nil
+}
+
+// --------------------------------------------------------------------
+
+type weightedValue struct {
+	value, weight float64
+}
+
+// imputeStats accumulates weighted statistics of a single attribute
+type imputeStats struct {
+	sum, weight float64
+	values      []weightedValue
+	counts      map[string]float64
+}
+
+func (s *imputeStats) add(v interface{}, weight float64) {
+	var f float64
+	if t, ok := v.(time.Time); ok {
+		f = float64(t.Unix())
+	} else if n, ok := numericValue(v); ok {
+		f = n
+	} else {
+		if s.counts == nil {
+			s.counts = make(map[string]float64)
+		}
+		s.counts[fmt.Sprint(v)] += weight
+		s.weight += weight
+		return
+	}
+
+	s.sum += f * weight
+	s.weight += weight
+	s.values = append(s.values, weightedValue{value: f, weight: weight})
+}
+
+// value returns the replacement value for attr or nil if no values were seen
+func (s *imputeStats) value(attr *Attribute, median bool) interface{} {
+	if s.weight == 0 {
+		return nil
+	}
+
+	switch attr.DataType {
+	case DataTypeNumeric:
+		if median {
+			return s.median()
+		}
+		return s.sum / s.weight
+	case DataTypeDate:
+		f := s.sum / s.weight
+		if median {
+			f = s.median()
+		}
+		return time.Unix(int64(math.Round(f)), 0).In(utc)
+	case DataTypeNominal:
+		return s.mode(attr.NominalValues)
+	}
+	return s.mode(nil)
+}
+
+// median returns the weighted median, averaging the two middle values
+// if the cumulative weight splits evenly between them
+func (s *imputeStats) median() float64 {
+	sort.Slice(s.values, func(i, j int) bool { return s.values[i].value < s.values[j].value })
+
+	half := s.weight / 2
+	cum := 0.0
+	for i, wv := range s.values {
+		cum += wv.weight
+		if cum > half {
+			return wv.value
+		}
+		if cum == half && i+1 < len(s.values) {
+			return (wv.value + s.values[i+1].value) / 2
+		}
+	}
+	return s.values[len(s.values)-1].value
+}
+
+// mode returns the most frequent value, ties are resolved by label
+// declaration order or lexically
+func (s *imputeStats) mode(labels []string) interface{} {
+	var keys []string
+	if labels != nil {
+		keys = labels
+	} else {
+		for k := range s.counts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	}
+
+	var best string
+	var bestWeight float64
+	for _, k := range keys {
+		if w := s.counts[k]; w > bestWeight {
+			best, bestWeight = k, w
+		}
+	}
+	if bestWeight == 0 {
+		return nil
+	}
+	return best
+}
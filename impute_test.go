@@ -0,0 +1,161 @@
+package arff
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReplaceMissing", func() {
+	rel := &Relation{Name: "test", Attributes: []Attribute{
+		{Name: "num", DataType: DataTypeNumeric},
+		{Name: "nom", DataType: DataTypeNominal, NominalValues: []string{"a", "b", "c"}},
+		{Name: "str", DataType: DataTypeString},
+		{Name: "date", DataType: DataTypeDate},
+		{Name: "class", DataType: DataTypeNominal, NominalValues: []string{"x", "y"}},
+	}}
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var data *Dataset
+
+	BeforeEach(func() {
+		data = &Dataset{Relation: *rel, Rows: []DataRow{
+			{Values: []interface{}{1.0, "b", "foo", t0, "x"}},
+			{Values: []interface{}{2.0, "b", "foo", t0.Add(2 * time.Hour), "x"}},
+			{Values: []interface{}{9.0, "c", "bar", nil, "y"}},
+			{Values: []interface{}{nil, nil, nil, nil, "y"}},
+			{Values: []interface{}{nil, nil, nil, nil, nil}},
+		}}
+	})
+
+	It("should replace by mean and mode", func() {
+		f := new(ReplaceMissing)
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Values).To(Equal(map[string]interface{}{
+			"num":   4.0,
+			"nom":   "b",
+			"str":   "foo",
+			"date":  t0.Add(time.Hour),
+			"class": "x",
+		}))
+
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Relation).To(Equal(data.Relation))
+		Expect(out.Rows[3].Values).To(Equal([]interface{}{4.0, "b", "foo", t0.Add(time.Hour), "y"}))
+		Expect(out.Rows[4].Values).To(Equal([]interface{}{4.0, "b", "foo", t0.Add(time.Hour), "x"}))
+		Expect(data.Rows[4].Values).To(Equal([]interface{}{nil, nil, nil, nil, nil}))
+	})
+
+	It("should replace by median", func() {
+		f := &ReplaceMissing{Median: true}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Values).To(HaveKeyWithValue("num", 2.0))
+		Expect(f.Values).To(HaveKeyWithValue("date", t0.Add(time.Hour)))
+
+		f = &ReplaceMissing{Median: true}
+		Expect(f.Fit(&data.Relation, (&Dataset{Rows: []DataRow{
+			{Values: []interface{}{1.0, nil, nil, nil, nil}},
+			{Values: []interface{}{4.0, nil, nil, nil, nil}},
+			{Values: []interface{}{2.0, nil, nil, nil, nil}},
+			{Values: []interface{}{3.0, nil, nil, nil, nil}},
+		}}).Iterator())).To(Succeed())
+		Expect(f.Values).To(Equal(map[string]interface{}{"num": 2.5}))
+	})
+
+	It("should consider weights", func() {
+		data.Rows[2].Weight = 4
+		f := new(ReplaceMissing)
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Values).To(HaveKeyWithValue("num", 6.5))
+		Expect(f.Values).To(HaveKeyWithValue("nom", "c"))
+	})
+
+	It("should keep supplied constants", func() {
+		f := &ReplaceMissing{Constants: map[string]interface{}{"num": -1.0, "str": "n/a"}}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Values).NotTo(HaveKey("num"))
+		Expect(f.Values).NotTo(HaveKey("str"))
+		Expect(f.Values).To(HaveKeyWithValue("nom", "b"))
+
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows[4].Values).To(Equal([]interface{}{-1.0, "b", "n/a", t0.Add(time.Hour), "x"}))
+
+		// constants only, without fitting
+		out, err = data.Filter(&ReplaceMissing{Constants: map[string]interface{}{"num": 0.0}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows[3].Values).To(Equal([]interface{}{0.0, nil, nil, nil, "y"}))
+
+		_, err = data.Filter(&ReplaceMissing{Constants: map[string]interface{}{"bad": 0.0}})
+		Expect(err).To(MatchError("unknown attribute 'bad'"))
+		_, err = data.Filter(&ReplaceMissing{Values: map[string]interface{}{"bad": 0.0}})
+		Expect(err).To(MatchError("unknown attribute 'bad'"))
+	})
+
+	It("should replace values on refit", func() {
+		f := new(ReplaceMissing)
+		Expect(f.Fit(&data.Relation, (&Dataset{Rows: []DataRow{
+			{Values: []interface{}{1.0, "a", "x", t0, "x"}},
+			{Values: []interface{}{3.0, "a", "x", t0, "x"}},
+		}}).Iterator())).To(Succeed())
+		Expect(f.Values).To(HaveKeyWithValue("num", 2.0))
+
+		Expect(f.Fit(&data.Relation, (&Dataset{Rows: []DataRow{
+			{Values: []interface{}{10.0, "b", "y", t0, "y"}},
+			{Values: []interface{}{30.0, "b", "y", t0, "y"}},
+		}}).Iterator())).To(Succeed())
+		Expect(f.Values).To(HaveKeyWithValue("num", 20.0))
+		Expect(f.Values).To(HaveKeyWithValue("nom", "b"))
+	})
+
+	It("should replace per class", func() {
+		f := &ReplaceMissing{PerClass: true}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.ClassValues).To(HaveLen(2))
+		Expect(f.ClassValues["x"]).To(HaveKeyWithValue("num", 1.5))
+		Expect(f.ClassValues["y"]).To(HaveKeyWithValue("num", 9.0))
+		Expect(f.ClassValues["y"]).NotTo(HaveKey("date"))
+		Expect(f.ClassValues["y"]).NotTo(HaveKey("class"))
+
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows[2].Values).To(Equal([]interface{}{9.0, "c", "bar", t0.Add(time.Hour), "y"}))
+		Expect(out.Rows[3].Values).To(Equal([]interface{}{9.0, "c", "bar", t0.Add(time.Hour), "y"}))
+		Expect(out.Rows[4].Values).To(Equal([]interface{}{4.0, "b", "foo", t0.Add(time.Hour), "x"}))
+	})
+
+	It("should use the designated class", func() {
+		data.Class = "nom"
+		f := &ReplaceMissing{PerClass: true}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.ClassValues).To(HaveLen(2))
+		Expect(f.ClassValues["b"]).To(HaveKeyWithValue("num", 1.5))
+	})
+
+	It("should stream between reader and writer", func() {
+		data, err := OpenDataset("testdata/labor.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		f := new(ReplaceMissing)
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+
+		r, err := Open("testdata/labor.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		buf := new(bytes.Buffer)
+		Expect(ApplyFilters(buf, r, f)).To(Succeed())
+
+		out, err := readDataset(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Relation.Attributes).To(Equal(data.Relation.Attributes))
+		Expect(out.Rows).To(HaveLen(len(data.Rows)))
+		for _, row := range out.Rows {
+			Expect(row.Values).NotTo(ContainElement(BeNil()))
+		}
+	})
+
+})
@@ -0,0 +1,92 @@
+package arff
+
+import "iter"
+
+// AllRows returns an iterator over all remaining rows of it, e.g.
+//
+//	for row, err := range arff.AllRows(it) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// An error is yielded once, as the final element.
+func AllRows(it Iterator) iter.Seq2[*DataRow, error] {
+	return func(yield func(*DataRow, error) bool) {
+		for it.Next() {
+			if !yield(it.Row(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// All returns an iterator over all remaining rows, see AllRows
+func (r *Reader) All() iter.Seq2[*DataRow, error] {
+	return AllRows(r)
+}
+
+// Rows returns an iterator over all remaining rows. It stops at the first
+// error, which must be checked with Err afterwards.
+func (r *Reader) Rows() iter.Seq[*DataRow] {
+	return func(yield func(*DataRow) bool) {
+		for r.Next() {
+			if !yield(r.Row()) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the dataset rows
+func (d *Dataset) All() iter.Seq2[*DataRow, error] {
+	return func(yield func(*DataRow, error) bool) {
+		for i := range d.Rows {
+			if !yield(&d.Rows[i], nil) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq initialises filters for rel and returns the output relation
+// together with an iterator over the filtered rows of seq.
+func FilterSeq(rel *Relation, seq iter.Seq2[*DataRow, error], filters ...Filter) (*Relation, iter.Seq2[*DataRow, error], error) {
+	for _, f := range filters {
+		out, err := f.Init(rel)
+		if err != nil {
+			return nil, nil, err
+		}
+		rel = out
+	}
+
+	return rel, func(yield func(*DataRow, error) bool) {
+		for row, err := range seq {
+			if err == nil {
+				if row, err = applyFilters(filters, row); err == nil && row == nil {
+					continue
+				}
+			}
+			if !yield(row, err) || err != nil {
+				return
+			}
+		}
+	}, nil
+}
+
+// AppendSeq appends all rows of seq, stopping at the first error
+func (w *Writer) AppendSeq(seq iter.Seq2[*DataRow, error]) error {
+	for row, err := range seq {
+		if err != nil {
+			return err
+		}
+		if err := w.Append(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package arff
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Iterators", func() {
+	const src = "@relation x\n@attribute a real\n@data\n1\n2\nnot a number\n4\n"
+
+	It("should iterate over reader rows", func() {
+		r, err := NewReader(strings.NewReader(src))
+		Expect(err).NotTo(HaveOccurred())
+
+		var values []interface{}
+		var errs []error
+		for row, err := range r.All() {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			values = append(values, row.Values[0])
+		}
+		Expect(values).To(Equal([]interface{}{1.0, 2.0}))
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(MatchError(ContainSubstring("LINE 6")))
+	})
+
+	It("should iterate over reader rows without errors", func() {
+		r, err := NewReader(strings.NewReader(src))
+		Expect(err).NotTo(HaveOccurred())
+
+		var values []interface{}
+		for row := range r.Rows() {
+			values = append(values, row.Values[0])
+		}
+		Expect(values).To(Equal([]interface{}{1.0, 2.0}))
+		Expect(r.Err()).To(HaveOccurred())
+	})
+
+	It("should stop early", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		n := 0
+		for row, err := range data.All() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(row).To(Equal(&data.Rows[n]))
+			if n++; n == 3 {
+				break
+			}
+		}
+		Expect(n).To(Equal(3))
+
+		n = 0
+		for range AllRows(data.Iterator()) {
+			n++
+		}
+		Expect(n).To(Equal(14))
+	})
+
+	It("should build pipelines", func() {
+		r, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		rel, seq, err := FilterSeq(&r.Relation, r.All(), dropRows("sunny"), &SelectAttributes{Names: []string{"outlook", "play"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		w, err := NewWriter(buf, rel)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.AppendSeq(seq)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+
+		data, err := readDataset(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Attributes).To(HaveLen(2))
+		Expect(data.Rows).To(HaveLen(9))
+		Expect(data.Rows[0].Values).To(Equal([]interface{}{"overcast", "yes"}))
+	})
+
+	It("should propagate errors through pipelines", func() {
+		data := &Dataset{
+			Relation: Relation{Name: "test", Attributes: []Attribute{{Name: "x", DataType: DataTypeNumeric}}},
+			Rows:     []DataRow{{Values: []interface{}{1.0}}},
+		}
+
+		rel, seq, err := FilterSeq(&data.Relation, data.All(), failRows{})
+		Expect(err).NotTo(HaveOccurred())
+
+		w, err := NewWriter(new(bytes.Buffer), rel)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.AppendSeq(seq)).To(MatchError("failed"))
+
+		r, err := NewReader(strings.NewReader(src))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.AppendSeq(r.All())).To(MatchError(ContainSubstring("LINE 6")))
+	})
+
+	It("should fail on invalid filters", func() {
+		data := &Dataset{Relation: Relation{Name: "test"}}
+		_, _, err := FilterSeq(&data.Relation, data.All(), &RemoveAttributes{Names: []string{"y"}})
+		Expect(err).To(MatchError("unknown attribute 'y'"))
+	})
+})
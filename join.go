@@ -0,0 +1,293 @@
+package arff
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JoinType defines how unmatched rows are treated
+type JoinType uint8
+
+const (
+	// InnerJoin emits matched rows only
+	InnerJoin JoinType = iota
+	// LeftJoin emits all left rows, unmatched rows have missing right values
+	LeftJoin
+)
+
+// JoinOptions configure Join
+type JoinOptions struct {
+	// Type is the join type. Default: InnerJoin
+	Type JoinType
+
+	// Keys are the names of the left key attributes
+	Keys []string
+
+	// RightKeys are the names of the right key attributes.
+	// Default: Keys
+	RightKeys []string
+
+	// RightPrefix is prepended to right attribute names which clash with
+	// left ones. Default: right relation name followed by a dot
+	RightPrefix string
+
+	// Sorted indicates that both inputs are sorted ascending by their keys,
+	// nominal keys by label. Sorted inputs are merge-joined without
+	// buffering the right side in memory.
+	Sorted bool
+}
+
+// Join joins the remaining rows of left and right on key attributes and
+// writes them to dst. The resulting relation contains all left attributes,
+// followed by all non-key attributes of right. Joined rows retain the
+// weights of left rows. Unless Sorted, right rows are loaded into memory
+// and hash-joined. Missing key values never match.
+func Join(dst io.Writer, left, right *Reader, opt *JoinOptions) error {
+	j, err := newJoiner(&left.Relation, &right.Relation, opt)
+	if err != nil {
+		return err
+	}
+
+	w, err := NewWriterSize(dst, j.rel, DefaultBufferSize)
+	if err != nil {
+		return err
+	}
+
+	if j.Sorted {
+		err = j.mergeJoin(w, left, right)
+	} else {
+		err = j.hashJoin(w, left, right)
+	}
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// JoinRelations returns the relation produced by joining left and right,
+// see Join
+func JoinRelations(left, right *Relation, opt *JoinOptions) (*Relation, error) {
+	j, err := newJoiner(left, right, opt)
+	if err != nil {
+		return nil, err
+	}
+	return j.rel, nil
+}
+
+// --------------------------------------------------------------------
+
+type joiner struct {
+	*JoinOptions
+	rel *Relation
+
+	leftKeys  []int // key positions in left rows
+	rightKeys []int // key positions in right rows
+	rightVals []int // non-key positions in right rows
+}
+
+func newJoiner(left, right *Relation, opt *JoinOptions) (*joiner, error) {
+	var oo JoinOptions
+	if opt != nil {
+		oo = *opt
+	}
+	if oo.RightKeys == nil {
+		oo.RightKeys = oo.Keys
+	}
+	if oo.RightPrefix == "" {
+		oo.RightPrefix = right.Name + "."
+	}
+	if len(oo.Keys) == 0 || len(oo.Keys) != len(oo.RightKeys) {
+		return nil, errInvalidJoinKeys
+	}
+
+	j := &joiner{JoinOptions: &oo}
+	isKey := make(map[int]bool)
+	for i, name := range oo.Keys {
+		lpos := left.AttributeIndex(name)
+		if lpos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+		rpos := right.AttributeIndex(oo.RightKeys[i])
+		if rpos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), oo.RightKeys[i])
+		}
+		if !joinCompatible(left.Attributes[lpos].DataType, right.Attributes[rpos].DataType) {
+			return nil, fmt.Errorf("key '%s' is %s, but '%s' is %s", name, left.Attributes[lpos].DataType, oo.RightKeys[i], right.Attributes[rpos].DataType)
+		}
+
+		j.leftKeys = append(j.leftKeys, lpos)
+		j.rightKeys = append(j.rightKeys, rpos)
+		isKey[rpos] = true
+	}
+
+	j.rel = &Relation{Name: left.Name, Class: left.Class}
+	j.rel.Attributes = append(j.rel.Attributes, left.Attributes...)
+	for pos, attr := range right.Attributes {
+		if isKey[pos] {
+			continue
+		}
+		if j.rel.AttributeIndex(attr.Name) > -1 {
+			attr.Name = oo.RightPrefix + attr.Name
+		}
+		if j.rel.AttributeIndex(attr.Name) > -1 {
+			return nil, fmt.Errorf("%s '%s'", errAttrRedefined.Error(), attr.Name)
+		}
+		j.rel.Attributes = append(j.rel.Attributes, attr)
+		j.rightVals = append(j.rightVals, pos)
+	}
+	return j, nil
+}
+
+func (j *joiner) hashJoin(w *Writer, left, right Iterator) error {
+	index := make(map[string][]*DataRow)
+	for right.Next() {
+		row := right.Row()
+		if key, ok := j.hashKey(row, j.rightKeys); ok {
+			index[key] = append(index[key], row)
+		}
+	}
+	if err := right.Err(); err != nil {
+		return err
+	}
+
+	for left.Next() {
+		row := left.Row()
+
+		var matches []*DataRow
+		if key, ok := j.hashKey(row, j.leftKeys); ok {
+			matches = index[key]
+		}
+		if err := j.emit(w, row, matches); err != nil {
+			return err
+		}
+	}
+	return left.Err()
+}
+
+func (j *joiner) mergeJoin(w *Writer, left, right Iterator) error {
+	var group []*DataRow // right rows sharing the current key
+	var next *DataRow    // first right row after group
+
+	if right.Next() {
+		next = right.Row()
+	}
+
+	for left.Next() {
+		row := left.Row()
+		if j.hasMissingKey(row, j.leftKeys) {
+			if err := j.emit(w, row, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// advance right side unless the current group matches
+		if len(group) == 0 || j.compareKeys(row, group[0]) != 0 {
+			group = group[:0]
+			for next != nil && (j.hasMissingKey(next, j.rightKeys) || j.compareKeys(row, next) > 0) {
+				next = j.advance(right)
+			}
+			for next != nil && j.compareKeys(row, next) == 0 {
+				group = append(group, next)
+				next = j.advance(right)
+			}
+			if err := right.Err(); err != nil {
+				return err
+			}
+		}
+
+		if err := j.emit(w, row, group); err != nil {
+			return err
+		}
+	}
+	return left.Err()
+}
+
+func (j *joiner) advance(it Iterator) *DataRow {
+	if it.Next() {
+		return it.Row()
+	}
+	return nil
+}
+
+// emit writes left joined with each match
+func (j *joiner) emit(w *Writer, left *DataRow, matches []*DataRow) error {
+	if len(matches) == 0 && j.Type == LeftJoin {
+		matches = []*DataRow{nil}
+	}
+
+	for _, right := range matches {
+		values := make([]interface{}, 0, len(j.rel.Attributes))
+		values = append(values, left.Values...)
+		for _, pos := range j.rightVals {
+			if right != nil {
+				values = append(values, right.Values[pos])
+			} else {
+				values = append(values, nil)
+			}
+		}
+
+		if err := w.Append(&DataRow{Values: values, Weight: left.Weight}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *joiner) hasMissingKey(row *DataRow, keys []int) bool {
+	for _, pos := range keys {
+		if row.Values[pos] == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// compareKeys compares left keys of a with right keys of b
+func (j *joiner) compareKeys(a, b *DataRow) int {
+	for i := range j.leftKeys {
+		if n := compareValues(a.Values[j.leftKeys[i]], b.Values[j.rightKeys[i]]); n != 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// hashKey encodes key values, missing values cannot be encoded
+func (j *joiner) hashKey(row *DataRow, keys []int) (string, bool) {
+	var b strings.Builder
+	for _, pos := range keys {
+		if row.Values[pos] == nil {
+			return "", false
+		}
+		writeKey(&b, row.Values[pos])
+	}
+	return b.String(), true
+}
+
+// writeKey encodes v, such that equal values have equal encodings
+func writeKey(b *strings.Builder, v interface{}) {
+	if f, ok := numericValue(v); ok {
+		// adding zero normalises negative zero
+		b.WriteString(strconv.FormatUint(math.Float64bits(f+0), 16))
+	} else if t, ok := v.(time.Time); ok {
+		b.WriteString(strconv.FormatInt(t.UnixNano(), 16))
+	} else if v == nil {
+		b.WriteByte('?')
+	} else {
+		b.WriteString(strconv.Quote(fmt.Sprint(v)))
+	}
+	b.WriteByte(0)
+}
+
+func joinCompatible(a, b DataType) bool {
+	if a == b {
+		return true
+	}
+	textual := func(t DataType) bool { return t == DataTypeString || t == DataTypeNominal }
+	return textual(a) && textual(b)
+}
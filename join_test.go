@@ -0,0 +1,104 @@
+package arff
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Join", func() {
+
+	const features = `@relation features
+@attribute id numeric
+@attribute size numeric
+@attribute color {red,blue}
+@data
+1,10,red
+2,20,blue
+2,21,red,{2}
+3,30,blue
+?,40,red
+`
+
+	const labels = `@relation labels
+@attribute id numeric
+@attribute color string
+@attribute class {yes,no}
+@data
+1,crimson,yes
+2,navy,no
+4,white,yes
+`
+
+	join := func(left, right string, opt *JoinOptions) string {
+		l, err := NewReader(strings.NewReader(left))
+		Expect(err).NotTo(HaveOccurred())
+		r, err := NewReader(strings.NewReader(right))
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		Expect(Join(buf, l, r, opt)).To(Succeed())
+		return buf.String()
+	}
+
+	It("should inner join", func() {
+		for _, sorted := range []bool{false, true} {
+			Expect(join(features, labels, &JoinOptions{Keys: []string{"id"}, Sorted: sorted})).To(Equal(`@RELATION features
+
+@ATTRIBUTE id NUMERIC
+@ATTRIBUTE size NUMERIC
+@ATTRIBUTE color {red,blue}
+@ATTRIBUTE labels.color STRING
+@ATTRIBUTE class {yes,no}
+
+@DATA
+1,10,red,crimson,yes
+2,20,blue,navy,no
+2,21,red,navy,no,{2}
+`), "sorted: %v", sorted)
+		}
+	})
+
+	It("should left join", func() {
+		for _, sorted := range []bool{false, true} {
+			Expect(join(features, labels, &JoinOptions{Keys: []string{"id"}, Type: LeftJoin, Sorted: sorted})).To(HaveSuffix(`@DATA
+1,10,red,crimson,yes
+2,20,blue,navy,no
+2,21,red,navy,no,{2}
+3,30,blue,?,?
+?,40,red,?,?
+`), "sorted: %v", sorted)
+		}
+	})
+
+	It("should join on multiple and differently named keys", func() {
+		right := "@relation r\n@attribute key numeric\n@attribute colour {red,blue}\n@attribute score numeric\n@data\n2,blue,0.5\n2,red,0.7\n"
+		out := join(features, right, &JoinOptions{
+			Keys:      []string{"id", "color"},
+			RightKeys: []string{"key", "colour"},
+		})
+		Expect(out).To(HaveSuffix("@ATTRIBUTE score NUMERIC\n\n@DATA\n2,20,blue,0.5\n2,21,red,0.7,{2}\n"))
+	})
+
+	It("should validate keys", func() {
+		_, err := JoinRelations(&Relation{Name: "a"}, &Relation{Name: "b"}, nil)
+		Expect(err).To(MatchError("invalid join keys"))
+
+		_, err = JoinRelations(
+			&Relation{Name: "a", Attributes: []Attribute{{Name: "id", DataType: DataTypeNumeric}}},
+			&Relation{Name: "b", Attributes: []Attribute{{Name: "id", DataType: DataTypeString}}},
+			&JoinOptions{Keys: []string{"id"}},
+		)
+		Expect(err).To(MatchError("key 'id' is NUMERIC, but 'id' is STRING"))
+
+		_, err = JoinRelations(
+			&Relation{Name: "a", Attributes: []Attribute{{Name: "id", DataType: DataTypeNumeric}}},
+			&Relation{Name: "b", Attributes: []Attribute{{Name: "id", DataType: DataTypeNumeric}}},
+			&JoinOptions{Keys: []string{"key"}},
+		)
+		Expect(err).To(MatchError("unknown attribute 'key'"))
+	})
+
+})
@@ -0,0 +1,160 @@
+package arff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// MergeOptions configure Merge and MergeRelations
+type MergeOptions struct {
+	// SortLabels sorts the union of nominal values alphabetically. By
+	// default, values are kept in the order in which they are first seen.
+	SortLabels bool
+}
+
+// SchemaConflict describes an incompatible attribute
+type SchemaConflict struct {
+	// Source is the index of the conflicting relation
+	Source int
+	// Attribute is the attribute name
+	Attribute string
+	// Reason describes the conflict
+	Reason string
+}
+
+// Error implements error interface
+func (c *SchemaConflict) Error() string {
+	return fmt.Sprintf("source %d: attribute '%s' %s", c.Source, c.Attribute, c.Reason)
+}
+
+// MergeError is returned when relations cannot be reconciled
+type MergeError struct {
+	Conflicts []SchemaConflict
+}
+
+// Error implements error interface
+func (e *MergeError) Error() string {
+	msgs := make([]string, len(e.Conflicts))
+	for i := range e.Conflicts {
+		msgs[i] = e.Conflicts[i].Error()
+	}
+	return "incompatible relations: " + strings.Join(msgs, "; ")
+}
+
+// MergeRelations reconciles rels into a single relation. All relations
+// must define the same attributes with the same data types, attribute
+// order and name are taken from the first. Nominal values are unioned.
+func MergeRelations(rels []*Relation, opt *MergeOptions) (*Relation, error) {
+	if opt == nil {
+		opt = new(MergeOptions)
+	}
+	if len(rels) == 0 {
+		return nil, errNoRelations
+	}
+
+	merged := *rels[0]
+	merged.Attributes = make([]Attribute, len(rels[0].Attributes))
+	copy(merged.Attributes, rels[0].Attributes)
+
+	var conflicts []SchemaConflict
+	for n, rel := range rels {
+		for _, attr := range rel.Attributes {
+			pos := merged.AttributeIndex(attr.Name)
+			if pos < 0 {
+				conflicts = append(conflicts, SchemaConflict{Source: n, Attribute: attr.Name, Reason: "is not defined by source 0"})
+				continue
+			}
+
+			target := &merged.Attributes[pos]
+			if target.DataType != attr.DataType {
+				conflicts = append(conflicts, SchemaConflict{
+					Source:    n,
+					Attribute: attr.Name,
+					Reason:    fmt.Sprintf("is %s, expected %s", attr.DataType, target.DataType),
+				})
+			} else if n != 0 && attr.DataType == DataTypeNominal {
+				target.NominalValues = unionStrings(target.NominalValues, attr.NominalValues)
+			}
+		}
+		for _, attr := range merged.Attributes {
+			if rel.AttributeIndex(attr.Name) < 0 {
+				conflicts = append(conflicts, SchemaConflict{Source: n, Attribute: attr.Name, Reason: "is missing"})
+			}
+		}
+	}
+	if len(conflicts) != 0 {
+		return nil, &MergeError{Conflicts: conflicts}
+	}
+
+	if opt.SortLabels {
+		for i := range merged.Attributes {
+			if vals := merged.Attributes[i].NominalValues; vals != nil {
+				vals = append([]string(nil), vals...)
+				sort.Strings(vals)
+				merged.Attributes[i].NominalValues = vals
+			}
+		}
+	}
+	return &merged, nil
+}
+
+// Merge concatenates the remaining rows of srcs into a single relation
+// written to dst. See MergeRelations for schema reconciliation rules, rows
+// are re-ordered to match the attribute order of the first source.
+func Merge(dst io.Writer, srcs []*Reader, opt *MergeOptions) error {
+	rels := make([]*Relation, len(srcs))
+	for i, src := range srcs {
+		rels[i] = &src.Relation
+	}
+
+	merged, err := MergeRelations(rels, opt)
+	if err != nil {
+		return err
+	}
+
+	w, err := NewWriterSize(dst, merged, DefaultBufferSize)
+	if err != nil {
+		return err
+	}
+
+	for _, src := range srcs {
+		index := make([]int, len(merged.Attributes))
+		for i, attr := range merged.Attributes {
+			index[i] = src.AttributeIndex(attr.Name)
+		}
+
+		for src.Next() {
+			row := src.Row()
+			values := make([]interface{}, len(index))
+			for i, pos := range index {
+				values[i] = row.Values[pos]
+			}
+			if err := w.Append(&DataRow{Values: values, Weight: row.Weight}); err != nil {
+				return err
+			}
+		}
+		if err := src.Err(); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// unionStrings returns a copy of a, extended by values of b missing from a
+func unionStrings(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a))
+	for _, s := range a {
+		seen[s] = struct{}{}
+	}
+
+	union := append([]string(nil), a...)
+	for _, s := range b {
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			union = append(union, s)
+		}
+	}
+	return union
+}
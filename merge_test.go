@@ -0,0 +1,63 @@
+package arff
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Merge", func() {
+
+	open := func(s string) *Reader {
+		r, err := NewReader(strings.NewReader(s))
+		Expect(err).NotTo(HaveOccurred())
+		return r
+	}
+
+	It("should merge relations", func() {
+		srcs := []*Reader{
+			open("@relation day1\n@attribute a numeric\n@attribute b {x,y}\n@data\n1,x\n2,y,{3}\n"),
+			open("@relation day2\n@attribute b {z,x}\n@attribute a numeric\n@data\nz,3\n"),
+		}
+
+		buf := new(bytes.Buffer)
+		Expect(Merge(buf, srcs, nil)).To(Succeed())
+		Expect(buf.String()).To(Equal(`@RELATION day1
+
+@ATTRIBUTE a NUMERIC
+@ATTRIBUTE b {x,y,z}
+
+@DATA
+1,x
+2,y,{3}
+3,z
+`))
+	})
+
+	It("should sort labels", func() {
+		rel, err := MergeRelations([]*Relation{
+			{Name: "a", Attributes: []Attribute{{Name: "b", DataType: DataTypeNominal, NominalValues: []string{"y", "x"}}}},
+			{Name: "b", Attributes: []Attribute{{Name: "b", DataType: DataTypeNominal, NominalValues: []string{"w"}}}},
+		}, &MergeOptions{SortLabels: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rel.Attributes[0].NominalValues).To(Equal([]string{"w", "x", "y"}))
+	})
+
+	It("should report conflicts", func() {
+		_, err := MergeRelations([]*Relation{
+			{Name: "a", Attributes: []Attribute{{Name: "a", DataType: DataTypeNumeric}, {Name: "b", DataType: DataTypeString}}},
+			{Name: "b", Attributes: []Attribute{{Name: "a", DataType: DataTypeString}, {Name: "c", DataType: DataTypeString}}},
+		}, nil)
+		Expect(err).To(BeAssignableToTypeOf(&MergeError{}))
+		Expect(err.(*MergeError).Conflicts).To(Equal([]SchemaConflict{
+			{Source: 1, Attribute: "a", Reason: "is STRING, expected NUMERIC"},
+			{Source: 1, Attribute: "c", Reason: "is not defined by source 0"},
+			{Source: 1, Attribute: "b", Reason: "is missing"},
+		}))
+		Expect(err).To(MatchError("incompatible relations: source 1: attribute 'a' is STRING, expected NUMERIC; " +
+			"source 1: attribute 'c' is not defined by source 0; source 1: attribute 'b' is missing"))
+	})
+
+})
@@ -2,6 +2,8 @@ package arff
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -18,6 +20,9 @@ type Reader struct {
 	own io.Closer
 	row *DataRow
 	err error
+
+	ctx   context.Context
+	check contextCheck
 }
 
 // Open reads a file at location
@@ -37,19 +42,90 @@ func Open(fname string) (*Reader, error) {
 	return rd, nil
 }
 
+// OpenHeader reads the relation header of the file at location,
+// without reading the data section
+func OpenHeader(fname string) (*Relation, error) {
+	file, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadHeader(file)
+}
+
+// ReadHeader reads the relation header from src, stopping at the @DATA
+// declaration
+func ReadHeader(src io.Reader) (*Relation, error) {
+	r, err := NewReader(src)
+	if err != nil {
+		return nil, err
+	}
+	return &r.Relation, nil
+}
+
+// ReaderOptions configure NewReaderOptions
+type ReaderOptions struct {
+	// ClassCommentPrefix marks the header comment which designates the
+	// class attribute. Comments naming unknown attributes are kept as
+	// regular comments. Default: "@class "
+	ClassCommentPrefix string
+
+	// NoClassComment disables class comments, keeping them as regular
+	// comments
+	NoClassComment bool
+}
+
+func (o *ReaderOptions) norm() *ReaderOptions {
+	var oo ReaderOptions
+	if o != nil {
+		oo = *o
+	}
+	if oo.ClassCommentPrefix == "" {
+		oo.ClassCommentPrefix = classCommentPrefix
+	}
+	if oo.NoClassComment {
+		oo.ClassCommentPrefix = ""
+	}
+	return &oo
+}
+
 // NewReader creates an ARFF reader from any io.Reader
 func NewReader(src io.Reader) (*Reader, error) {
+	return NewReaderOptions(src, nil)
+}
+
+// NewReaderOptions creates an ARFF reader like NewReader, with custom
+// options
+func NewReaderOptions(src io.Reader, opt *ReaderOptions) (*Reader, error) {
+	opt = opt.norm()
 	r := &Reader{
 		src: src,
 		scn: &scanner{Reader: bufio.NewReader(src)},
 	}
 
-	if err := r.parseHeader(); err != nil {
+	if err := r.parseHeader(opt.ClassCommentPrefix); err != nil {
 		return nil, r.wrapError(err)
 	}
 	return r, nil
 }
 
+// NewReaderContext creates an ARFF reader like NewReader, which stops
+// reading rows once ctx is done. The context is checked periodically
+// and its error is returned by Err.
+func NewReaderContext(ctx context.Context, src io.Reader) (*Reader, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r, err := NewReader(src)
+	if err != nil {
+		return nil, err
+	}
+	r.ctx = ctx
+	return r, nil
+}
+
 // ReadAll reads all data rows at once, the equivalent of:
 //     var rows []DataRow
 //     for r.Next() {
@@ -57,8 +133,16 @@ func NewReader(src io.Reader) (*Reader, error) {
 //     }
 //     err := r.Err()
 func (r *Reader) ReadAll() ([]DataRow, error) {
+	return r.ReadAllContext(r.context())
+}
+
+// ReadAllContext reads all data rows at once, like ReadAll, but stops
+// once ctx is done
+func (r *Reader) ReadAllContext(ctx context.Context) ([]DataRow, error) {
+	r.check.Reset()
+
 	var rows []DataRow
-	for r.Next() {
+	for r.NextContext(ctx) {
 		rows = append(rows, *r.Row())
 	}
 	if err := r.Err(); err != nil {
@@ -67,6 +151,34 @@ func (r *Reader) ReadAll() ([]DataRow, error) {
 	return rows, nil
 }
 
+// Count consumes the remaining data rows and returns their number.
+// Rows are not parsed or validated, which makes counting considerably
+// faster than iterating with Next.
+func (r *Reader) Count() (int, error) {
+	return r.CountContext(r.context())
+}
+
+// CountContext consumes the remaining data rows and returns their number,
+// like Count, but stops once ctx is done
+func (r *Reader) CountContext(ctx context.Context) (int, error) {
+	r.check.Reset()
+
+	n := 0
+	for {
+		if err := r.check.Err(ctx); err != nil {
+			r.err, r.row = err, nil
+			return n, err
+		}
+		if err := r.scn.SkipDataRow(); err == io.EOF {
+			return n, nil
+		} else if err != nil {
+			r.markFailed(err)
+			return n, r.err
+		}
+		n++
+	}
+}
+
 // Close closes the reader
 func (r *Reader) Close() error {
 	if r.own != nil {
@@ -77,58 +189,176 @@ func (r *Reader) Close() error {
 
 // Next returns true if can advance the row cursor
 func (r *Reader) Next() bool {
+	return r.NextContext(r.context())
+}
+
+// NextContext returns true if can advance the row cursor, like Next, but
+// periodically checks ctx instead of the reader's context. Once ctx is
+// done, it returns false and Err returns the context error.
+func (r *Reader) NextContext(ctx context.Context) bool {
+	if err := r.check.Err(ctx); err != nil {
+		r.err, r.row = err, nil
+		return false
+	}
+
 	strs, err := r.scn.DataRow()
 	if err != nil {
 		r.markFailed(err)
 		return false
 	}
 
-	nvals, nattrs := len(strs), len(r.Attributes)
-	if nvals < nattrs {
-		r.markFailed(errAttrMismatch)
+	row, err := r.parseRow(strs)
+	if err != nil {
+		r.markFailed(err)
 		return false
 	}
 
-	var row DataRow
-	for i, attr := range r.Attributes {
-		v, err := attr.parse(strs[i])
-		if err != nil {
+	r.row = row
+	return true
+}
+
+// Validate consumes the remaining data rows and checks them, including
+// the labels of nominal values. Unlike Next, it continues past invalid
+// rows and returns an error for each of them. The final error is non-nil
+// if reading fails.
+func (r *Reader) Validate() ([]error, error) {
+	return r.ValidateContext(r.context())
+}
+
+// ValidateContext consumes and checks the remaining data rows, like
+// Validate, but stops once ctx is done
+func (r *Reader) ValidateContext(ctx context.Context) ([]error, error) {
+	r.check.Reset()
+
+	var errs []error
+	for {
+		if err := r.check.Err(ctx); err != nil {
+			r.err, r.row = err, nil
+			return errs, err
+		}
+
+		strs, err := r.scn.DataRow()
+		if err == io.EOF {
+			return errs, nil
+		} else if err != nil && err != errBadSyntax {
 			r.markFailed(err)
-			return false
+			return errs, r.err
+		}
+
+		if err == nil {
+			var row *DataRow
+			if row, err = r.parseRow(strs); err == nil {
+				err = r.checkLabels(row)
+			}
+		}
+		if err != nil {
+			errs = append(errs, r.wrapError(err))
 		}
-		row.Values = append(row.Values, v)
+	}
+}
+
+// parseRow parses the fields of a data row
+func (r *Reader) parseRow(strs []string) (*DataRow, error) {
+	var row DataRow
+	var rest []string
+	var err error
+	if r.scn.Sparse {
+		row.Values, rest, err = r.parseSparse(strs)
+	} else {
+		row.Values, rest, err = r.parseDense(strs)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// check if there is a weight
-	if nvals > nattrs {
-		weight := strs[nattrs]
+	if len(rest) != 0 {
+		weight := rest[0]
 		plast := len(weight) - 1
 		if len(weight) < 2 || weight[0] != '{' || weight[plast] != '}' {
-			r.markFailed(errInvalidWeight)
-			return false
+			return nil, errInvalidWeight
 		}
 
 		num, err := strconv.ParseFloat(weight[1:plast], 64)
 		if err != nil || num < 0 {
-			r.markFailed(errInvalidWeight)
-			return false
+			return nil, errInvalidWeight
 		}
 		row.Weight = num
 	}
+	return &row, nil
+}
 
-	r.row = &row
-	return true
+// checkLabels checks that nominal values are declared labels
+func (r *Reader) checkLabels(row *DataRow) error {
+	for i, attr := range r.Attributes {
+		s, ok := row.Values[i].(string)
+		if !ok || attr.DataType != DataTypeNominal || len(attr.NominalValues) == 0 {
+			continue
+		}
+		if indexOf(attr.NominalValues, s) < 0 {
+			return fmt.Errorf("value '%s' is not a label of '%s'", s, attr.Name)
+		}
+	}
+	return nil
 }
 
 // Row returns the current DataRow
 func (r *Reader) Row() *DataRow { return r.row }
 
+// parseDense parses values of a regular row, returning remaining fields
+func (r *Reader) parseDense(strs []string) ([]interface{}, []string, error) {
+	if len(strs) < len(r.Attributes) {
+		return nil, nil, errAttrMismatch
+	}
+
+	values := make([]interface{}, 0, len(r.Attributes))
+	for i, attr := range r.Attributes {
+		v, err := attr.parse(strs[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, v)
+	}
+	return values, strs[len(r.Attributes):], nil
+}
+
+// parseSparse parses values of a sparse row, returning remaining fields.
+// Omitted values are zero, or the first label of nominal attributes.
+func (r *Reader) parseSparse(strs []string) ([]interface{}, []string, error) {
+	values := make([]interface{}, len(r.Attributes))
+	for i, attr := range r.Attributes {
+		values[i] = attr.zero()
+	}
+
+	for n, pair := range strs {
+		if pair[0] == '{' {
+			return values, strs[n:], nil
+		}
+
+		pos := strings.IndexAny(pair, " \t")
+		if pos < 0 {
+			return nil, nil, errBadSyntax
+		}
+		i, err := strconv.Atoi(pair[:pos])
+		if err != nil || i < 0 || i >= len(r.Attributes) {
+			return nil, nil, errAttrMismatch
+		}
+		if values[i], err = r.Attributes[i].parse(strings.TrimSpace(pair[pos+1:])); err != nil {
+			return nil, nil, err
+		}
+	}
+	return values, nil, nil
+}
+
 // Err returns an error if any
 func (r *Reader) Err() error {
 	return r.err
 }
 
-func (r *Reader) parseHeader() error {
+func (r *Reader) parseHeader(classPrefix string) error {
+	var comments []string
+	var classes []classComment
+
 	for {
 		fields, err := r.scn.HeaderFields()
 		if err != nil {
@@ -136,6 +366,13 @@ func (r *Reader) parseHeader() error {
 		}
 
 		if len(fields) == 0 {
+			if !r.scn.HasComment {
+				continue
+			}
+			if name, ok := parseClassComment(r.scn.Comment, classPrefix); ok {
+				classes = append(classes, classComment{name: name, attr: pendingComment, pos: len(comments)})
+			}
+			comments = append(comments, r.scn.Comment)
 			continue
 		}
 		switch strings.ToUpper(fields[0]) {
@@ -144,6 +381,8 @@ func (r *Reader) parseHeader() error {
 				return errMissingRelName
 			}
 			r.Relation.Name = unquote(fields[1])
+			r.Relation.Comments, comments = r.scn.appendComment(comments), nil
+			settleClassComments(classes, -1, 0)
 		case "@ATTRIBUTE":
 			if len(fields) < 2 {
 				return errMissingAttrName
@@ -152,8 +391,12 @@ func (r *Reader) parseHeader() error {
 			}
 
 			attr := Attribute{
-				Name: unquote(fields[1]),
+				Name:     unquote(fields[1]),
+				Comments: r.scn.appendComment(comments),
 			}
+			comments = nil
+			settleClassComments(classes, len(r.Relation.Attributes), 0)
+
 			switch strings.ToUpper(fields[2]) {
 			case "NUMERIC", "REAL", "INTEGER":
 				attr.DataType = DataTypeNumeric
@@ -169,6 +412,9 @@ func (r *Reader) parseHeader() error {
 			}
 			r.Relation.Attributes = append(r.Relation.Attributes, attr)
 		case "@DATA":
+			settleClassComments(classes, -1, len(r.Relation.Comments))
+			r.Relation.Comments = append(r.Relation.Comments, r.scn.appendComment(comments)...)
+			r.applyClassComment(classes)
 			return nil
 		default:
 			return errBadSyntax
@@ -176,6 +422,64 @@ func (r *Reader) parseHeader() error {
 	}
 }
 
+// pendingComment marks class comments not yet attached to a declaration
+const pendingComment = -2
+
+// classComment is a header comment designating a class attribute, located
+// at pos of the comments of attribute attr or of the relation if -1
+type classComment struct {
+	name      string
+	attr, pos int
+}
+
+// settleClassComments attaches pending class comments to attr, offsetting
+// their positions
+func settleClassComments(classes []classComment, attr, offset int) {
+	for i := range classes {
+		if classes[i].attr == pendingComment {
+			classes[i].attr = attr
+			classes[i].pos += offset
+		}
+	}
+}
+
+// applyClassComment sets the class attribute from the last class comment
+// which names a known attribute and removes that comment
+func (r *Reader) applyClassComment(classes []classComment) {
+	for i := len(classes) - 1; i >= 0; i-- {
+		c := classes[i]
+		if r.Relation.AttributeIndex(c.name) < 0 {
+			continue
+		}
+
+		comments := &r.Relation.Comments
+		if c.attr > -1 {
+			comments = &r.Relation.Attributes[c.attr].Comments
+		}
+		if *comments = append((*comments)[:c.pos], (*comments)[c.pos+1:]...); len(*comments) == 0 {
+			*comments = nil
+		}
+		r.Relation.Class = c.name
+		return
+	}
+}
+
+// parseClassComment extracts the class attribute name from a comment
+func parseClassComment(comment, prefix string) (string, bool) {
+	n := len(prefix)
+	if n == 0 || len(comment) < n || !strings.EqualFold(comment[:n], prefix) {
+		return "", false
+	}
+	return unquote(comment[n:]), true
+}
+
+func (r *Reader) context() context.Context {
+	if r.ctx != nil {
+		return r.ctx
+	}
+	return context.Background()
+}
+
 func (r *Reader) markFailed(err error) {
 	if err != io.EOF {
 		r.err = r.wrapError(err)
@@ -195,6 +499,23 @@ func (r *Reader) wrapError(err error) error {
 type scanner struct {
 	*bufio.Reader
 	Lineno int
+
+	// Comment holds the comment of the last header line, if HasComment
+	Comment    string
+	HasComment bool
+
+	// Sparse is set if the last data row is in sparse format. Its fields
+	// are "index value" pairs, optionally followed by the weight.
+	Sparse bool
+}
+
+// appendComment appends the inline comment of the last header line, if
+// any
+func (s *scanner) appendComment(comments []string) []string {
+	if s.HasComment {
+		return append(comments, s.Comment)
+	}
+	return comments
 }
 
 func (s *scanner) DataRow() ([]string, error) {
@@ -205,15 +526,47 @@ func (s *scanner) DataRow() ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
+		line = line[:len(line)-1]
 
-		if vv := scanCSV(line[:len(line)-1]); len(vv) != 0 {
-			return vv, nil
+		if c := rowStart(line); c == 0 || c == '%' {
+			continue
+		}
+		if s.Sparse = firstNonSpace(line) == '{'; s.Sparse {
+			return scanSparse(line)
+		}
+		return scanCSV(line), nil
+	}
+}
+
+// SkipDataRow advances past the next data row without parsing it
+func (s *scanner) SkipDataRow() error {
+	for {
+		s.Lineno++
+
+		var head byte
+		for {
+			chunk, err := s.ReadSlice('\n')
+			if err != nil && err != bufio.ErrBufferFull {
+				return err
+			}
+			if head == 0 {
+				head = rowStart(chunk)
+			}
+			if err == nil {
+				break
+			}
+		}
+
+		// apply the same rules as DataRow
+		if head != 0 && head != '%' {
+			return nil
 		}
 	}
 }
 
 func (s *scanner) HeaderFields() ([]string, error) {
 	s.Lineno++
+	s.Comment, s.HasComment = "", false
 
 	line, err := s.ReadBytes('\n')
 	if err != nil {
@@ -263,6 +616,10 @@ MainLoop:
 			}
 		case '%':
 			if !inQuote && !inBracket {
+				s.Comment = strings.TrimRight(string(line[i+size:]), " \t\r\n")
+				s.Comment = strings.TrimPrefix(s.Comment, " ")
+				s.HasComment = true
+
 				line = line[:i]
 				break MainLoop
 			}
@@ -279,6 +636,63 @@ MainLoop:
 	return fields, nil
 }
 
+// firstNonSpace returns the first non-whitespace byte of line or 0
+func firstNonSpace(line []byte) byte {
+	for _, c := range line {
+		switch c {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return c
+		}
+	}
+	return 0
+}
+
+// rowStart returns the first byte of line which is neither whitespace nor
+// a separator or 0. Data rows skip lines starting with 0 or '%'.
+func rowStart(line []byte) byte {
+	for _, c := range line {
+		switch c {
+		case ' ', '\t', ',', '\n':
+		default:
+			return c
+		}
+	}
+	return 0
+}
+
+// scanSparse splits a sparse row into its pairs and the trailing weight
+func scanSparse(line []byte) ([]string, error) {
+	var fields []string
+	var inQuote bool
+
+	start := bytes.IndexByte(line, '{') + 1
+	prv := byte(0)
+	for i := start; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '\'':
+			if !inQuote {
+				inQuote = true
+			} else if prv != '\\' {
+				inQuote = false
+			}
+		case ',', '}':
+			if inQuote {
+				break
+			}
+			if pair := strings.TrimSpace(string(line[start:i])); pair != "" {
+				fields = append(fields, pair)
+			}
+			start = i + 1
+			if c == '}' {
+				return append(fields, scanCSV(line[start:])...), nil
+			}
+		}
+		prv = line[i]
+	}
+	return nil, errBadSyntax
+}
+
 func scanCSV(line []byte) []string {
 	min := 0
 	prv := rune(0)
@@ -303,12 +717,12 @@ MainLoop:
 		case ',':
 			if !inQuote {
 				if min < i {
-					fields = append(fields, string(line[min:i]))
+					fields = append(fields, string(bytes.TrimRight(line[min:i], " \t")))
 				}
 				min = i + size
 			}
 		case ' ', '\t':
-			if !inQuote {
+			if !inQuote && min == i {
 				min = i + size
 			}
 		case '%':
@@ -322,7 +736,9 @@ MainLoop:
 		i += size
 	}
 	if min < len(line) {
-		fields = append(fields, string(line[min:]))
+		if field := bytes.TrimRight(line[min:], " \t"); len(field) != 0 {
+			fields = append(fields, string(field))
+		}
 	}
 
 	return fields
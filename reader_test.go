@@ -2,6 +2,9 @@ package arff
 
 import (
 	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
 	"strings"
 	"time"
 
@@ -36,6 +39,41 @@ var _ = Describe("Reader", func() {
 		}))
 	})
 
+	It("should parse comments", func() {
+		r, err := NewReader(strings.NewReader("% first\n%\n%  second \n@relation x % named\n\n% about z\n@attribute z real % inline\n@attribute y real\n\n% trailing\n@data % rows\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation.Comments).To(Equal([]string{"first", "", " second", "named", "trailing", "rows"}))
+		Expect(r.Attributes).To(Equal([]Attribute{
+			{Name: "z", DataType: DataTypeNumeric, Comments: []string{"about z", "inline"}},
+			{Name: "y", DataType: DataTypeNumeric},
+		}))
+	})
+
+	It("should parse class comments", func() {
+		r, err := NewReader(strings.NewReader("% @CLASS 'the z'\n@relation x\n@attribute 'the z' real\n@attribute y real\n@data\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation.Class).To(Equal("the z"))
+		Expect(r.Relation.Comments).To(BeEmpty())
+		Expect(r.ClassIndex()).To(Equal(0))
+
+		r, err = NewReader(strings.NewReader("% @class is important\n@relation x\n% @class y\n% @class w\n@attribute y real\n@attribute z real\n@data\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation.Class).To(Equal("y"))
+		Expect(r.Relation.Comments).To(Equal([]string{"@class is important"}))
+		Expect(r.Relation.Attributes[0].Comments).To(Equal([]string{"@class w"}))
+		Expect(r.Relation.Attributes[1].Comments).To(BeEmpty())
+
+		r, err = NewReaderOptions(strings.NewReader("% @class y\n% class: z\n@relation x\n@attribute y real\n@attribute z real\n@data\n"), &ReaderOptions{ClassCommentPrefix: "class: "})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation.Class).To(Equal("z"))
+		Expect(r.Relation.Comments).To(Equal([]string{"@class y"}))
+
+		r, err = NewReaderOptions(strings.NewReader("% @class y\n@relation x\n@attribute y real\n@data\n"), &ReaderOptions{NoClassComment: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation.Class).To(BeEmpty())
+		Expect(r.Relation.Comments).To(Equal([]string{"@class y"}))
+	})
+
 	It("should fail on bad syntax", func() {
 		_, err := NewReader(strings.NewReader("@relation x\nnot a comment\n"))
 		Expect(err).To(MatchError("LINE 2: bad syntax"))
@@ -63,6 +101,84 @@ var _ = Describe("Reader", func() {
 		Expect(err).To(MatchError("LINE 7: attribute mismatch"))
 	})
 
+	It("should parse sparse rows", func() {
+		r, err := NewReader(strings.NewReader(`@relation x
+@attribute num NUMERIC
+@attribute str STRING
+@attribute nom {a,b}
+@data
+{}
+{ 2 b , 1 'x, y}' }
+{0 3.5}, {2} % weighted
+{3 1}
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Next()).To(BeTrue())
+		Expect(r.Row()).To(Equal(&DataRow{Values: []interface{}{0.0, "", "a"}}))
+		Expect(r.Next()).To(BeTrue())
+		Expect(r.Row()).To(Equal(&DataRow{Values: []interface{}{0.0, "x, y}", "b"}}))
+		Expect(r.Next()).To(BeTrue())
+		Expect(r.Row()).To(Equal(&DataRow{Values: []interface{}{3.5, "", "a"}, Weight: 2}))
+		Expect(r.Next()).To(BeFalse())
+		Expect(r.Err()).To(MatchError("LINE 9: attribute mismatch"))
+	})
+
+	It("should count rows", func() {
+		r, err := Open("testdata/iris.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		Expect(r.Next()).To(BeTrue())
+		Expect(r.Count()).To(Equal(9))
+		Expect(r.Next()).To(BeFalse())
+		Expect(r.Err()).NotTo(HaveOccurred())
+
+		r, err = NewReader(strings.NewReader("@relation x\n@attribute a string\n@data\n" + strings.Repeat(" ", 5000) + "x\n\n  % comment\ny\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Count()).To(Equal(2))
+
+		const blank = "@relation x\n@attribute a numeric\n@attribute b numeric\n@data\n1,2\n,\n , \t,% c\n3,4\n"
+		r, err = NewReader(strings.NewReader(blank))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Count()).To(Equal(2))
+
+		r, err = NewReader(strings.NewReader(blank))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.ReadAll()).To(HaveLen(2))
+	})
+
+	It("should validate rows", func() {
+		r, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+		Expect(r.Validate()).To(BeEmpty())
+
+		r, err = NewReader(strings.NewReader("@relation x\n@attribute a numeric\n@attribute b {p,q}\n@data\n1,z\nx,p\n{1 q\n2,q,{-1}\n3,p\n"))
+		Expect(err).NotTo(HaveOccurred())
+		errs, err := r.Validate()
+		Expect(err).NotTo(HaveOccurred())
+		var msgs []string
+		for _, e := range errs {
+			msgs = append(msgs, e.Error())
+		}
+		Expect(msgs).To(Equal([]string{
+			"LINE 5: value 'z' is not a label of 'b'",
+			"LINE 6: value 'x' is not numeric",
+			"LINE 7: bad syntax",
+			"LINE 8: invalid weight definition",
+		}))
+	})
+
+	It("should read headers", func() {
+		rel, err := OpenHeader("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rel.Name).To(Equal("weather"))
+		Expect(rel.Attributes).To(HaveLen(5))
+
+		_, err = ReadHeader(strings.NewReader("@relation x\n@attribute a bad\n@data\n"))
+		Expect(err).To(MatchError("LINE 2: invalid data-type"))
+	})
+
 	DescribeTable("should read datasets",
 		func(fixture string, rel *Relation, exp []DataRow) {
 			file, err := Open(fixture)
@@ -90,6 +206,15 @@ var _ = Describe("Reader", func() {
 					{Name: "petalWidth", DataType: DataTypeNumeric},
 					{Name: "class", DataType: DataTypeNominal, NominalValues: []string{"iris setosa", "iris versicolor", "iris virginica"}},
 				},
+				Comments: []string{
+					"1. Title: Iris Plants Database",
+					"",
+					"2. Sources:",
+					"     (a) Creator: R.A. Fisher",
+					"     (b) Donor: Michael Marshall (MARSHALL%PLU@io.arc.nasa.gov)",
+					"     (c) Date: July, 1988",
+					"",
+				},
 			},
 			[]DataRow{
 				{Values: []interface{}{5.1, 3.5, 1.4, 0.2, "iris setosa"}},
@@ -170,6 +295,7 @@ var _ = Describe("Reader", func() {
 					{Name: "contribution-to-health-plan", DataType: DataTypeNominal, NominalValues: []string{"none", "half", "full"}},
 					{Name: "class", DataType: DataTypeNominal, NominalValues: []string{"bad", "good"}},
 				},
+				Comments: leadingComments("testdata/labor.arff"),
 			},
 			[]DataRow{
 				{
@@ -206,8 +332,97 @@ var _ = Describe("Reader", func() {
 		),
 	)
 
+	Describe("with context", func() {
+		numbers := func(n int) string {
+			var b strings.Builder
+			b.WriteString("@relation x\n@attribute a real\n@data\n")
+			for i := 0; i < n; i++ {
+				fmt.Fprintln(&b, i)
+			}
+			return b.String()
+		}
+
+		It("should fail on done contexts", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := NewReaderContext(ctx, strings.NewReader(numbers(1)))
+			Expect(err).To(Equal(context.Canceled))
+		})
+
+		It("should stop reading once cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			r, err := NewReaderContext(ctx, strings.NewReader(numbers(1000)))
+			Expect(err).NotTo(HaveOccurred())
+
+			n := 0
+			for r.Next() {
+				if n++; n == 300 {
+					cancel()
+				}
+			}
+			Expect(n).To(Equal(512))
+			Expect(r.Row()).To(BeNil())
+			Expect(r.Err()).To(Equal(context.Canceled))
+			Expect(r.Next()).To(BeFalse())
+		})
+
+		It("should check contexts periodically", func() {
+			r, err := NewReader(strings.NewReader(numbers(10)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.NextContext(context.Background())).To(BeTrue())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(r.NextContext(ctx)).To(BeTrue())
+
+			_, err = r.ReadAllContext(ctx)
+			Expect(err).To(Equal(context.Canceled))
+		})
+
+		It("should read and count", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			r, err := NewReaderContext(ctx, strings.NewReader(numbers(10)))
+			Expect(err).NotTo(HaveOccurred())
+			rows, err := r.ReadAll()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(HaveLen(10))
+
+			r, err = NewReaderContext(ctx, strings.NewReader(numbers(10)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Count()).To(Equal(10))
+
+			r, err = NewReaderContext(ctx, strings.NewReader(numbers(10)))
+			Expect(err).NotTo(HaveOccurred())
+			cancel()
+			_, err = r.Count()
+			Expect(err).To(Equal(context.Canceled))
+		})
+	})
+
 })
 
+// leadingComments extracts the comment block at the top of a fixture
+func leadingComments(fname string) []string {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		panic(err)
+	}
+
+	var comments []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "%") {
+			break
+		}
+		comments = append(comments, strings.TrimPrefix(strings.TrimRight(line[1:], " "), " "))
+	}
+	return comments
+}
+
 var _ = Describe("scanner", func() {
 
 	It("should parse simple header fields", func() {
@@ -250,6 +465,8 @@ var _ = Describe("scanner", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(s.Lineno).To(Equal(1))
 		Expect(fields).To(Equal([]string{`@keyword`, `value`}))
+		Expect(s.HasComment).To(BeTrue())
+		Expect(s.Comment).To(Equal("comment starts here"))
 	})
 
 	It("should parse data rows", func() {
@@ -265,4 +482,33 @@ var _ = Describe("scanner", func() {
 		}))
 	})
 
+	It("should parse unquoted values with spaces", func() {
+		s := &scanner{Reader: bufio.NewReader(strings.NewReader(
+			"new york, 1 ,x \na b ,1.1 % comment\n",
+		))}
+
+		fields, err := s.DataRow()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields).To(Equal([]string{`new york`, `1`, `x`}))
+
+		fields, err = s.DataRow()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields).To(Equal([]string{`a b`, `1.1`}))
+	})
+
+	It("should parse sparse data rows", func() {
+		s := &scanner{Reader: bufio.NewReader(strings.NewReader(
+			"a,b\n{0 x, 1 y} , {2}\n",
+		))}
+
+		_, err := s.DataRow()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Sparse).To(BeFalse())
+
+		fields, err := s.DataRow()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Sparse).To(BeTrue())
+		Expect(fields).To(Equal([]string{`0 x`, `1 y`, `{2}`}))
+	})
+
 })
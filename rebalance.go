@@ -0,0 +1,310 @@
+package arff
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ResampleOptions configure Undersample, Oversample, SMOTE and Reweight
+type ResampleOptions struct {
+	// Class is the name of the class attribute.
+	// Default: the class attribute of the dataset relation
+	Class string
+
+	// Ratio is the targeted size of the minority classes relative to the
+	// majority class, between 0 and 1. Default: 1
+	Ratio float64
+
+	// Neighbours is the number of nearest neighbours SMOTE interpolates
+	// with. Default: 5
+	Neighbours int
+
+	// Seed initialises the random number generator, identical seeds
+	// produce identical samples
+	Seed int64
+}
+
+func (o *ResampleOptions) norm() *ResampleOptions {
+	var oo ResampleOptions
+	if o != nil {
+		oo = *o
+	}
+	if oo.Ratio <= 0 || oo.Ratio > 1 {
+		oo.Ratio = 1
+	}
+	if oo.Neighbours < 1 {
+		oo.Neighbours = 5
+	}
+	return &oo
+}
+
+// Undersample randomly discards rows of the majority classes until no
+// class has more than 1/Ratio times the rows of the smallest class. Rows
+// retain their original order and are shared with data. Rows with a
+// missing class are retained.
+func Undersample(data *Dataset, opt *ResampleOptions) (*Dataset, error) {
+	opt = opt.norm()
+
+	groups, err := classGroups(data, opt.Class)
+	if err != nil {
+		return nil, err
+	}
+
+	min := len(data.Rows)
+	for _, group := range groups {
+		if len(group) < min {
+			min = len(group)
+		}
+	}
+	limit := int(math.Ceil(float64(min) / opt.Ratio))
+
+	rnd := rand.New(rand.NewSource(opt.Seed))
+	drop := make([]bool, len(data.Rows))
+	for _, group := range groups {
+		if len(group) <= limit {
+			continue
+		}
+		for _, n := range rnd.Perm(len(group))[limit:] {
+			drop[group[n]] = true
+		}
+	}
+
+	out := &Dataset{Relation: data.Relation}
+	for i, row := range data.Rows {
+		if !drop[i] {
+			out.Rows = append(out.Rows, row)
+		}
+	}
+	return out, nil
+}
+
+// Oversample randomly duplicates rows of the minority classes until each
+// class has at least Ratio times the rows of the largest class.
+// Duplicates are appended after the original rows and share their values.
+func Oversample(data *Dataset, opt *ResampleOptions) (*Dataset, error) {
+	opt = opt.norm()
+
+	groups, err := classGroups(data, opt.Class)
+	if err != nil {
+		return nil, err
+	}
+
+	rnd := rand.New(rand.NewSource(opt.Seed))
+	out := &Dataset{Relation: data.Relation, Rows: append([]DataRow(nil), data.Rows...)}
+	for _, group := range groups {
+		for n := oversampleTarget(groups, opt.Ratio) - len(group); n > 0; n-- {
+			out.Rows = append(out.Rows, data.Rows[group[rnd.Intn(len(group))]])
+		}
+	}
+	return out, nil
+}
+
+// SMOTE oversamples the minority classes with synthetic rows until each
+// class has at least Ratio times the rows of the largest class, see
+// Chawla et al. (2002). Each synthetic row interpolates the numeric
+// attributes of a sample row and one of its nearest neighbours of the same
+// class at a random point; all other values are copied from the sample.
+// Distances are computed over the numeric attributes scaled to their
+// ranges. Synthetic rows are appended after the original rows.
+func SMOTE(data *Dataset, opt *ResampleOptions) (*Dataset, error) {
+	opt = opt.norm()
+
+	groups, err := classGroups(data, opt.Class)
+	if err != nil {
+		return nil, err
+	}
+	class, _ := classIndex(&data.Relation, opt.Class)
+
+	s := newSmoter(data, class)
+	rnd := rand.New(rand.NewSource(opt.Seed))
+	out := &Dataset{Relation: data.Relation, Rows: append([]DataRow(nil), data.Rows...)}
+	for _, group := range groups {
+		n := oversampleTarget(groups, opt.Ratio) - len(group)
+		if n < 1 {
+			continue
+		}
+
+		// cycle through the group in random order, so that each row
+		// seeds a similar number of synthetic rows
+		order := rnd.Perm(len(group))
+		neighbours := make(map[int][]int)
+		for j := 0; j < n; j++ {
+			i := group[order[j%len(order)]]
+			nn, ok := neighbours[i]
+			if !ok {
+				nn = s.Nearest(i, group, opt.Neighbours)
+				neighbours[i] = nn
+			}
+
+			sample := &data.Rows[i]
+			if len(nn) == 0 {
+				out.Rows = append(out.Rows, *sample)
+				continue
+			}
+			out.Rows = append(out.Rows, s.Interpolate(sample, &data.Rows[nn[rnd.Intn(len(nn))]], rnd.Float64()))
+		}
+	}
+	return out, nil
+}
+
+// Reweight scales the row weights so that all classes have the same total
+// weight, preserving the overall weight. Rows with a missing class retain
+// their weights. Rows are copied, but share their values with data.
+func Reweight(data *Dataset, opt *ResampleOptions) (*Dataset, error) {
+	opt = opt.norm()
+
+	groups, err := classGroups(data, opt.Class)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0.0
+	totals := make([]float64, len(groups))
+	for n, group := range groups {
+		for _, i := range group {
+			totals[n] += data.Rows[i].weight()
+		}
+		total += totals[n]
+	}
+
+	out := &Dataset{Relation: data.Relation, Rows: append([]DataRow(nil), data.Rows...)}
+	for n, group := range groups {
+		if totals[n] == 0 {
+			continue
+		}
+		scale := total / float64(len(groups)) / totals[n]
+		for _, i := range group {
+			out.Rows[i].Weight = out.Rows[i].weight() * scale
+		}
+	}
+	return out, nil
+}
+
+// --------------------------------------------------------------------
+
+// classGroups returns row indices grouped by class in order of first
+// appearance, omitting rows with missing classes
+func classGroups(data *Dataset, name string) ([][]int, error) {
+	class, err := classIndex(&data.Relation, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups [][]int
+	index := make(map[interface{}]int)
+	for i, row := range data.Rows {
+		if class < 0 || class >= len(row.Values) {
+			return nil, errAttrMismatch
+		}
+
+		key := row.Values[class]
+		if key == nil {
+			continue
+		}
+
+		n, ok := index[key]
+		if !ok {
+			n = len(groups)
+			index[key] = n
+			groups = append(groups, nil)
+		}
+		groups[n] = append(groups[n], i)
+	}
+	return groups, nil
+}
+
+// oversampleTarget returns the targeted number of rows per class
+func oversampleTarget(groups [][]int, ratio float64) int {
+	max := 0
+	for _, group := range groups {
+		if len(group) > max {
+			max = len(group)
+		}
+	}
+	return int(math.Ceil(float64(max) * ratio))
+}
+
+type smoter struct {
+	data    *Dataset
+	numeric []int     // positions of numeric attributes
+	ranges  []float64 // value ranges of numeric attributes
+}
+
+func newSmoter(data *Dataset, class int) *smoter {
+	s := &smoter{data: data}
+	for pos, attr := range data.Attributes {
+		if pos == class || attr.DataType != DataTypeNumeric {
+			continue
+		}
+
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, row := range data.Rows {
+			if v, ok := numericValue(row.Values[pos]); ok {
+				min, max = math.Min(min, v), math.Max(max, v)
+			}
+		}
+		s.numeric = append(s.numeric, pos)
+		s.ranges = append(s.ranges, max-min)
+	}
+	return s
+}
+
+// Nearest returns the k nearest neighbours of row i among candidates
+func (s *smoter) Nearest(i int, candidates []int, k int) []int {
+	type neighbour struct {
+		index int
+		dist  float64
+	}
+
+	nn := make([]neighbour, 0, len(candidates))
+	for _, j := range candidates {
+		if j != i {
+			nn = append(nn, neighbour{index: j, dist: s.distance(&s.data.Rows[i], &s.data.Rows[j])})
+		}
+	}
+	sort.SliceStable(nn, func(a, b int) bool { return nn[a].dist < nn[b].dist })
+	if len(nn) > k {
+		nn = nn[:k]
+	}
+
+	index := make([]int, len(nn))
+	for n, x := range nn {
+		index[n] = x.index
+	}
+	return index
+}
+
+// Interpolate returns a synthetic row between sample and neighbour at gap
+func (s *smoter) Interpolate(sample, neighbour *DataRow, gap float64) DataRow {
+	values := make([]interface{}, len(sample.Values))
+	copy(values, sample.Values)
+
+	for _, pos := range s.numeric {
+		a, ok1 := numericValue(sample.Values[pos])
+		b, ok2 := numericValue(neighbour.Values[pos])
+		if ok1 && ok2 {
+			values[pos] = a + gap*(b-a)
+		}
+	}
+	return DataRow{Values: values, Weight: sample.Weight}
+}
+
+// distance returns the squared euclidean distance between scaled numeric
+// values, missing values differ by 1
+func (s *smoter) distance(a, b *DataRow) float64 {
+	sum := 0.0
+	for n, pos := range s.numeric {
+		x, ok1 := numericValue(a.Values[pos])
+		y, ok2 := numericValue(b.Values[pos])
+
+		d := 1.0
+		if ok1 && ok2 {
+			if d = 0; s.ranges[n] > 0 {
+				d = (x - y) / s.ranges[n]
+			}
+		}
+		sum += d * d
+	}
+	return sum
+}
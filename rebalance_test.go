@@ -0,0 +1,114 @@
+package arff
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rebalance", func() {
+	var data *Dataset
+
+	BeforeEach(func() {
+		var err error
+		data, err = OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	classCounts := func(d *Dataset) map[interface{}]int {
+		counts := make(map[interface{}]int)
+		for _, row := range d.Rows {
+			counts[row.Values[4]]++
+		}
+		return counts
+	}
+
+	It("should undersample", func() {
+		out, err := Undersample(data, &ResampleOptions{Seed: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Relation).To(Equal(data.Relation))
+		Expect(classCounts(out)).To(Equal(map[interface{}]int{"yes": 5, "no": 5}))
+
+		again, err := Undersample(data, &ResampleOptions{Seed: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(out))
+
+		out, err = Undersample(data, &ResampleOptions{Ratio: 0.6})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(classCounts(out)).To(Equal(map[interface{}]int{"yes": 9, "no": 5}))
+
+		out, err = Undersample(data, &ResampleOptions{Class: "outlook"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows).To(HaveLen(12))
+	})
+
+	It("should oversample", func() {
+		out, err := Oversample(data, &ResampleOptions{Seed: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows[:14]).To(Equal(data.Rows))
+		Expect(classCounts(out)).To(Equal(map[interface{}]int{"yes": 9, "no": 9}))
+		for _, row := range out.Rows[14:] {
+			Expect(data.Rows).To(ContainElement(row))
+		}
+
+		out, err = Oversample(data, &ResampleOptions{Ratio: 0.7})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(classCounts(out)).To(Equal(map[interface{}]int{"yes": 9, "no": 7}))
+	})
+
+	It("should oversample with SMOTE", func() {
+		out, err := SMOTE(data, &ResampleOptions{Seed: 1, Neighbours: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows[:14]).To(Equal(data.Rows))
+		Expect(classCounts(out)).To(Equal(map[interface{}]int{"yes": 9, "no": 9}))
+
+		for _, row := range out.Rows[14:] {
+			Expect([]interface{}{"sunny", "overcast", "rainy"}).To(ContainElement(row.Values[0]))
+			Expect(row.Values[1]).To(BeNumerically(">=", 65))
+			Expect(row.Values[1]).To(BeNumerically("<=", 85))
+			Expect(row.Values[2]).To(BeNumerically(">=", 70))
+			Expect(row.Values[2]).To(BeNumerically("<=", 95))
+			Expect(row.Values[4]).To(Equal("no"))
+		}
+
+		again, err := SMOTE(data, &ResampleOptions{Seed: 1, Neighbours: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(out))
+
+		var buf bytes.Buffer
+		_, err = out.WriteTo(&buf)
+		Expect(err).NotTo(HaveOccurred())
+		written, err := readDataset(&buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written.Rows).To(HaveLen(18))
+	})
+
+	It("should reweight", func() {
+		out, err := Reweight(data, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows).To(HaveLen(14))
+		Expect(data.Rows[0].Weight).To(Equal(0.0))
+
+		totals := make(map[interface{}]float64)
+		for _, row := range out.Rows {
+			totals[row.Values[4]] += row.Weight
+		}
+		Expect(totals["yes"]).To(BeNumerically("~", 7, 1e-9))
+		Expect(totals["no"]).To(BeNumerically("~", 7, 1e-9))
+		Expect(out.Rows[0].Weight).To(BeNumerically("~", 1.4, 1e-9))
+	})
+
+	It("should skip missing classes", func() {
+		data.Rows[0].Values[4] = nil
+		out, err := Undersample(data, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows[0]).To(Equal(data.Rows[0]))
+		Expect(classCounts(out)).To(Equal(map[interface{}]int{nil: 1, "yes": 4, "no": 4}))
+	})
+
+	It("should validate options", func() {
+		_, err := SMOTE(data, &ResampleOptions{Class: "unknown"})
+		Expect(err).To(MatchError("unknown attribute 'unknown'"))
+	})
+})
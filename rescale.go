@@ -0,0 +1,187 @@
+package arff
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScaleMethod is a rescaling method
+type ScaleMethod uint8
+
+const (
+	// MinMax normalises values to the [0,1] range
+	MinMax ScaleMethod = iota
+	// ZScore standardises values to zero mean and unit variance
+	ZScore
+)
+
+// Scaling is a fitted linear transformation: (x - Offset) / Scale
+type Scaling struct {
+	Offset, Scale float64
+}
+
+// Rescale is a Filter which rescales numeric attributes. Scalings are
+// fitted on one dataset and may then be applied to others. Rescale is
+// serialisable, e.g. using encoding/json, to persist fitted scalings.
+type Rescale struct {
+	// Method is the rescaling method. Default: MinMax
+	Method ScaleMethod
+
+	// Attributes are the names of the attributes to rescale.
+	// Default: all numeric attributes
+	Attributes []string
+
+	// Scalings maps attribute names to their scalings. They are
+	// computed by Fit or may be supplied.
+	Scalings map[string]Scaling
+
+	index  []int
+	params []Scaling
+}
+
+// Normalize returns a filter which normalises values of the named
+// attributes to [0,1]
+func Normalize(attrs ...string) *Rescale {
+	return &Rescale{Method: MinMax, Attributes: attrs}
+}
+
+// Standardize returns a filter which standardises values of the named
+// attributes to zero mean and unit variance
+func Standardize(attrs ...string) *Rescale {
+	return &Rescale{Method: ZScore, Attributes: attrs}
+}
+
+// Fit computes scalings for attributes of rel from all remaining rows of
+// it, replacing existing ones. Row weights are taken into account and
+// missing values are ignored.
+func (f *Rescale) Fit(rel *Relation, it Iterator) error {
+	index, err := f.attributes(rel)
+	if err != nil {
+		return err
+	}
+
+	stats := make([]rescaleStats, len(index))
+	for i := range stats {
+		stats[i].min, stats[i].max = math.Inf(1), math.Inf(-1)
+	}
+	for it.Next() {
+		row := it.Row()
+		weight := row.weight()
+		for i, pos := range index {
+			if v := row.Values[pos]; v != nil {
+				x, ok := numericValue(v)
+				if !ok {
+					return fmt.Errorf("value '%v' is not numeric", v)
+				}
+				stats[i].add(x, weight)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	f.Scalings = make(map[string]Scaling, len(index))
+	for i, pos := range index {
+		f.Scalings[rel.Attributes[pos].Name] = stats[i].scaling(f.Method)
+	}
+	return nil
+}
+
+// Init implements Filter
+func (f *Rescale) Init(in *Relation) (*Relation, error) {
+	index, err := f.attributes(in)
+	if err != nil {
+		return nil, err
+	}
+
+	f.index, f.params = index, f.params[:0]
+	for _, pos := range index {
+		name := in.Attributes[pos].Name
+		s, ok := f.Scalings[name]
+		if !ok {
+			return nil, fmt.Errorf("%s '%s'", errUnfittedAttr.Error(), name)
+		}
+		f.params = append(f.params, s)
+	}
+	return in, nil
+}
+
+// Apply implements Filter
+func (f *Rescale) Apply(row *DataRow) (*DataRow, error) {
+	for i, pos := range f.index {
+		v := row.Values[pos]
+		if v == nil {
+			continue
+		}
+
+		x, ok := numericValue(v)
+		if !ok {
+			return nil, fmt.Errorf("value '%v' is not numeric", v)
+		}
+		if s := f.params[i]; s.Scale != 0 {
+			row.Values[pos] = (x - s.Offset) / s.Scale
+		} else {
+			row.Values[pos] = 0.0
+		}
+	}
+	return row, nil
+}
+
+func (f *Rescale) attributes(rel *Relation) ([]int, error) {
+	var index []int
+	if len(f.Attributes) == 0 {
+		for pos, attr := range rel.Attributes {
+			if attr.DataType == DataTypeNumeric {
+				index = append(index, pos)
+			}
+		}
+		return index, nil
+	}
+
+	for _, name := range f.Attributes {
+		pos := rel.AttributeIndex(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+		if rel.Attributes[pos].DataType != DataTypeNumeric {
+			return nil, fmt.Errorf("%s '%s'", errNonNumericAttr.Error(), name)
+		}
+		index = append(index, pos)
+	}
+	return index, nil
+}
+
+// --------------------------------------------------------------------
+
+// rescaleStats accumulates weighted statistics, using West's
+// incremental algorithm for mean and variance
+type rescaleStats struct {
+	min, max          float64
+	weight, mean, ssd float64
+}
+
+func (s *rescaleStats) add(x, weight float64) {
+	s.min, s.max = math.Min(s.min, x), math.Max(s.max, x)
+
+	s.weight += weight
+	delta := x - s.mean
+	s.mean += delta * weight / s.weight
+	s.ssd += weight * delta * (x - s.mean)
+}
+
+func (s *rescaleStats) scaling(method ScaleMethod) Scaling {
+	if s.weight == 0 {
+		return Scaling{}
+	}
+
+	switch method {
+	case ZScore:
+		var stddev float64
+		if s.weight > 1 {
+			stddev = math.Sqrt(s.ssd / (s.weight - 1))
+		}
+		return Scaling{Offset: s.mean, Scale: stddev}
+	}
+	return Scaling{Offset: s.min, Scale: s.max - s.min}
+}
@@ -0,0 +1,107 @@
+package arff
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rescale", func() {
+	var train, test *Dataset
+
+	BeforeEach(func() {
+		rel := Relation{Name: "test", Attributes: []Attribute{
+			{Name: "a", DataType: DataTypeNumeric},
+			{Name: "b", DataType: DataTypeNumeric},
+			{Name: "c", DataType: DataTypeString},
+		}}
+		train = &Dataset{Relation: rel, Rows: []DataRow{
+			{Values: []interface{}{2.0, 5.0, "x"}},
+			{Values: []interface{}{4.0, 5.0, "y"}},
+			{Values: []interface{}{6.0, nil, "z"}},
+		}}
+		test = &Dataset{Relation: rel, Rows: []DataRow{
+			{Values: []interface{}{8.0, 3.0, "x"}},
+			{Values: []interface{}{nil, 5.0, "y"}},
+		}}
+	})
+
+	It("should normalise", func() {
+		f := Normalize()
+		Expect(f.Fit(&train.Relation, train.Iterator())).To(Succeed())
+		Expect(f.Scalings).To(Equal(map[string]Scaling{
+			"a": {Offset: 2, Scale: 4},
+			"b": {Offset: 5, Scale: 0},
+		}))
+
+		out, err := train.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Relation).To(Equal(train.Relation))
+		Expect(out.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{0.0, 0.0, "x"}},
+			{Values: []interface{}{0.5, 0.0, "y"}},
+			{Values: []interface{}{1.0, nil, "z"}},
+		}))
+
+		out, err = test.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{1.5, 0.0, "x"}},
+			{Values: []interface{}{nil, 0.0, "y"}},
+		}))
+	})
+
+	It("should standardise", func() {
+		f := Standardize("a")
+		Expect(f.Fit(&train.Relation, train.Iterator())).To(Succeed())
+		Expect(f.Scalings).To(Equal(map[string]Scaling{
+			"a": {Offset: 4, Scale: 2},
+		}))
+
+		out, err := test.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{2.0, 3.0, "x"}},
+			{Values: []interface{}{nil, 5.0, "y"}},
+		}))
+	})
+
+	It("should consider weights", func() {
+		train.Rows[2].Weight = 2
+		f := Standardize("a")
+		Expect(f.Fit(&train.Relation, train.Iterator())).To(Succeed())
+		Expect(f.Scalings["a"].Offset).To(Equal(4.5))
+		Expect(f.Scalings["a"].Scale).To(BeNumerically("~", 1.9149, 0.0001))
+	})
+
+	It("should validate attributes", func() {
+		Expect(Normalize("x").Fit(&train.Relation, train.Iterator())).To(MatchError("unknown attribute 'x'"))
+		Expect(Normalize("c").Fit(&train.Relation, train.Iterator())).To(MatchError("non-numeric attribute 'c'"))
+
+		_, err := test.Filter(Normalize("a"))
+		Expect(err).To(MatchError("unfitted attribute 'a'"))
+	})
+
+	It("should serialise fitted parameters", func() {
+		f := Standardize()
+		Expect(f.Fit(&train.Relation, train.Iterator())).To(Succeed())
+
+		bin, err := json.Marshal(f)
+		Expect(err).NotTo(HaveOccurred())
+
+		g := new(Rescale)
+		Expect(json.Unmarshal(bin, g)).To(Succeed())
+		Expect(g.Method).To(Equal(ZScore))
+		Expect(g.Scalings).To(Equal(f.Scalings))
+
+		r, err := NewReader(bytes.NewBufferString("@relation test\n@attribute a numeric\n@attribute b numeric\n@attribute c string\n@data\n8,3,x\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		Expect(ApplyFilters(buf, r, g)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("@DATA\n2,0,x\n"))
+	})
+
+})
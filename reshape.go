@@ -0,0 +1,290 @@
+package arff
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RemoveAttributes is a Filter which removes the named attributes. If
+// the class attribute is removed, the output relation has no explicit
+// class, see Relation.ClassIndex.
+type RemoveAttributes struct {
+	Names []string
+
+	keep []int
+}
+
+// Init implements Filter
+func (f *RemoveAttributes) Init(in *Relation) (*Relation, error) {
+	remove := make(map[int]bool, len(f.Names))
+	for _, name := range f.Names {
+		pos := in.AttributeIndex(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+		remove[pos] = true
+	}
+
+	f.keep = f.keep[:0]
+	for pos := range in.Attributes {
+		if !remove[pos] {
+			f.keep = append(f.keep, pos)
+		}
+	}
+	return project(in, f.keep), nil
+}
+
+// Apply implements Filter
+func (f *RemoveAttributes) Apply(row *DataRow) (*DataRow, error) {
+	return projectRow(row, f.keep), nil
+}
+
+// SelectAttributes is a Filter which retains the named attributes only,
+// in the given order. The class attribute is retained by name, wherever
+// it is moved. If it is not selected, the output relation has no
+// explicit class, see Relation.ClassIndex.
+type SelectAttributes struct {
+	Names []string
+
+	keep []int
+}
+
+// Init implements Filter
+func (f *SelectAttributes) Init(in *Relation) (*Relation, error) {
+	f.keep = f.keep[:0]
+	for _, name := range f.Names {
+		pos := in.AttributeIndex(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+		f.keep = append(f.keep, pos)
+	}
+
+	out := project(in, f.keep)
+	for i, attr := range out.Attributes {
+		if out.AttributeIndex(attr.Name) != i {
+			return nil, fmt.Errorf("%s '%s'", errAttrRedefined.Error(), attr.Name)
+		}
+	}
+	return out, nil
+}
+
+// Apply implements Filter
+func (f *SelectAttributes) Apply(row *DataRow) (*DataRow, error) {
+	return projectRow(row, f.keep), nil
+}
+
+// RenameAttributes is a Filter which renames attributes
+type RenameAttributes struct {
+	// Names maps old to new attribute names
+	Names map[string]string
+}
+
+// Init implements Filter
+func (f *RenameAttributes) Init(in *Relation) (*Relation, error) {
+	for name := range f.Names {
+		if in.AttributeIndex(name) < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+	}
+
+	out := &Relation{Name: in.Name, Comments: in.Comments, Class: in.Class}
+	if name, ok := f.Names[in.Class]; ok {
+		out.Class = name
+	}
+	for _, attr := range in.Attributes {
+		if name, ok := f.Names[attr.Name]; ok {
+			attr.Name = name
+		}
+		if out.AttributeIndex(attr.Name) > -1 {
+			return nil, fmt.Errorf("%s '%s'", errAttrRedefined.Error(), attr.Name)
+		}
+		out.Attributes = append(out.Attributes, attr)
+	}
+	return out, nil
+}
+
+// Apply implements Filter
+func (f *RenameAttributes) Apply(row *DataRow) (*DataRow, error) {
+	return row, nil
+}
+
+// RetypeAttributes is a Filter which changes attribute data types. Values
+// are converted via their string representation, values which cannot be
+// converted cause an error.
+type RetypeAttributes struct {
+	// Types maps attribute names to new data types
+	Types map[string]DataType
+
+	// Labels maps names of nominal attributes to their labels. Unless
+	// supplied, labels are retained from nominal attributes or collected
+	// by Fit in the order of their first occurrence.
+	Labels map[string][]string
+
+	index  []int
+	types  []DataType
+	labels []map[string]bool
+}
+
+// Fit collects labels of attributes retyped to nominal from all
+// remaining rows of it, unless they were supplied
+func (f *RetypeAttributes) Fit(rel *Relation, it Iterator) error {
+	var index []int
+	for name, dt := range f.Types {
+		pos := rel.AttributeIndex(name)
+		if pos < 0 {
+			return fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+		if _, ok := f.Labels[name]; ok || dt != DataTypeNominal || rel.Attributes[pos].DataType == DataTypeNominal {
+			continue
+		}
+		index = append(index, pos)
+	}
+
+	labels := make([][]string, len(index))
+	seen := make([]map[string]bool, len(index))
+	for i := range seen {
+		seen[i] = make(map[string]bool)
+	}
+	for it.Next() {
+		row := it.Row()
+		for i, pos := range index {
+			s, err := formatValue(row.Values[pos])
+			if err != nil {
+				return err
+			}
+			if row.Values[pos] != nil && !seen[i][s] {
+				seen[i][s] = true
+				labels[i] = append(labels[i], s)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if f.Labels == nil {
+		f.Labels = make(map[string][]string, len(index))
+	}
+	for i, pos := range index {
+		f.Labels[rel.Attributes[pos].Name] = labels[i]
+	}
+	return nil
+}
+
+// Init implements Filter
+func (f *RetypeAttributes) Init(in *Relation) (*Relation, error) {
+	out := *in
+	out.Attributes = append([]Attribute(nil), in.Attributes...)
+
+	f.index, f.types, f.labels = f.index[:0], f.types[:0], f.labels[:0]
+	for pos := range out.Attributes {
+		attr := &out.Attributes[pos]
+		dt, ok := f.Types[attr.Name]
+		if !ok {
+			continue
+		}
+
+		var labels []string
+		switch {
+		case dt != DataTypeNominal:
+		case f.Labels[attr.Name] != nil:
+			labels = f.Labels[attr.Name]
+		case attr.DataType == DataTypeNominal:
+			labels = attr.NominalValues
+		default:
+			return nil, fmt.Errorf("%s '%s'", errUnfittedAttr.Error(), attr.Name)
+		}
+
+		var set map[string]bool
+		if labels != nil {
+			set = make(map[string]bool, len(labels))
+			for _, label := range labels {
+				set[label] = true
+			}
+		}
+
+		attr.DataType, attr.NominalValues = dt, labels
+		f.index = append(f.index, pos)
+		f.types = append(f.types, dt)
+		f.labels = append(f.labels, set)
+	}
+
+	for name := range f.Types {
+		if out.AttributeIndex(name) < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+	}
+	return &out, nil
+}
+
+// Apply implements Filter
+func (f *RetypeAttributes) Apply(row *DataRow) (*DataRow, error) {
+	for i, pos := range f.index {
+		v := row.Values[pos]
+		if v == nil {
+			continue
+		}
+
+		s, err := formatValue(v)
+		if err != nil {
+			return nil, err
+		}
+
+		switch f.types[i] {
+		case DataTypeNumeric:
+			if _, ok := numericValue(v); ok {
+				continue
+			}
+			num, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("value '%s' is not numeric", s)
+			}
+			row.Values[pos] = num
+		case DataTypeDate:
+			if _, ok := v.(time.Time); ok {
+				continue
+			}
+			dt, err := time.ParseInLocation(iso8691DateFormat, s, utc)
+			if err != nil {
+				return nil, fmt.Errorf("value '%s' is not an ISO8601 date", s)
+			}
+			row.Values[pos] = dt
+		case DataTypeNominal:
+			if !f.labels[i][s] {
+				return nil, fmt.Errorf("value '%s' is not a declared label", s)
+			}
+			row.Values[pos] = s
+		default:
+			row.Values[pos] = s
+		}
+	}
+	return row, nil
+}
+
+// --------------------------------------------------------------------
+
+// project returns a relation with the attributes at positions keep. The
+// class attribute, including an implicit one, is designated by name if it
+// is kept and left blank otherwise.
+func project(in *Relation, keep []int) *Relation {
+	out := &Relation{Name: in.Name, Comments: in.Comments}
+	class := in.ClassIndex()
+	for _, pos := range keep {
+		out.Attributes = append(out.Attributes, in.Attributes[pos])
+		if pos == class {
+			out.Class = in.Attributes[pos].Name
+		}
+	}
+	return out
+}
+
+// projectRow returns a row with the values at positions keep
+func projectRow(row *DataRow, keep []int) *DataRow {
+	values := make([]interface{}, len(keep))
+	for i, pos := range keep {
+		values[i] = row.Values[pos]
+	}
+	return &DataRow{Values: values, Weight: row.Weight}
+}
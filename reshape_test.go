@@ -0,0 +1,144 @@
+package arff
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RemoveAttributes", func() {
+
+	It("should remove attributes", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		data.Class = "play"
+
+		out, err := data.Filter(&RemoveAttributes{Names: []string{"humidity", "outlook"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes).To(Equal([]Attribute{data.Attributes[1], data.Attributes[3], data.Attributes[4]}))
+		Expect(out.Class).To(Equal("play"))
+		Expect(out.Rows[0]).To(Equal(DataRow{Values: []interface{}{85.0, "FALSE", "no"}}))
+
+		out, err = data.Filter(&RemoveAttributes{Names: []string{"play"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Class).To(BeEmpty())
+
+		data.Class = ""
+		out, err = data.Filter(&RemoveAttributes{Names: []string{"outlook"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Class).To(Equal("play"))
+
+		_, err = data.Filter(&RemoveAttributes{Names: []string{"x"}})
+		Expect(err).To(MatchError("unknown attribute 'x'"))
+	})
+
+})
+
+var _ = Describe("SelectAttributes", func() {
+
+	It("should select and reorder attributes", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		out, err := data.Filter(&SelectAttributes{Names: []string{"play", "outlook"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes).To(Equal([]Attribute{data.Attributes[4], data.Attributes[0]}))
+		Expect(out.Class).To(Equal("play"))
+		Expect(out.ClassIndex()).To(Equal(0))
+		Expect(out.Rows[0]).To(Equal(DataRow{Values: []interface{}{"no", "sunny"}}))
+
+		_, err = data.Filter(&SelectAttributes{Names: []string{"play", "play"}})
+		Expect(err).To(MatchError("redefined attribute 'play'"))
+	})
+
+})
+
+var _ = Describe("RenameAttributes", func() {
+
+	It("should rename attributes", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		data.Class = "play"
+
+		out, err := data.Filter(&RenameAttributes{Names: map[string]string{"play": "label", "windy": "wind"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes[3].Name).To(Equal("wind"))
+		Expect(out.Attributes[4].Name).To(Equal("label"))
+		Expect(out.Class).To(Equal("label"))
+		Expect(out.Rows).To(Equal(data.Rows))
+		Expect(data.Attributes[4].Name).To(Equal("play"))
+
+		_, err = data.Filter(&RenameAttributes{Names: map[string]string{"play": "outlook"}})
+		Expect(err).To(MatchError("redefined attribute 'outlook'"))
+	})
+
+})
+
+var _ = Describe("RetypeAttributes", func() {
+	var data *Dataset
+
+	BeforeEach(func() {
+		data = &Dataset{Relation: Relation{Name: "test", Attributes: []Attribute{
+			{Name: "str", DataType: DataTypeString},
+			{Name: "nom", DataType: DataTypeNominal, NominalValues: []string{"1.5", "2"}},
+			{Name: "date", DataType: DataTypeString},
+		}}, Rows: []DataRow{
+			{Values: []interface{}{"b", "2", "2018-01-01T00:00:00"}},
+			{Values: []interface{}{nil, "1.5", nil}},
+			{Values: []interface{}{"a", nil, "2018-02-01T00:00:00"}},
+			{Values: []interface{}{"b", "2", nil}},
+		}}
+	})
+
+	It("should convert strings to nominals", func() {
+		f := &RetypeAttributes{Types: map[string]DataType{"str": DataTypeNominal}}
+		_, err := data.Filter(f)
+		Expect(err).To(MatchError("unfitted attribute 'str'"))
+
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Labels).To(Equal(map[string][]string{"str": {"b", "a"}}))
+
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes[0]).To(Equal(Attribute{Name: "str", DataType: DataTypeNominal, NominalValues: []string{"b", "a"}}))
+		Expect(out.Rows).To(Equal(data.Rows))
+
+		f.Labels["str"] = []string{"a"}
+		_, err = data.Filter(f)
+		Expect(err).To(MatchError("value 'b' is not a declared label"))
+	})
+
+	It("should convert nominals to strings and numerics", func() {
+		f := &RetypeAttributes{Types: map[string]DataType{"nom": DataTypeNumeric, "date": DataTypeDate}}
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes[1]).To(Equal(Attribute{Name: "nom", DataType: DataTypeNumeric}))
+		Expect(out.Rows[0].Values).To(Equal([]interface{}{"b", 2.0, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)}))
+		Expect(out.Rows[1].Values).To(Equal([]interface{}{nil, 1.5, nil}))
+
+		back, err := out.Filter(&RetypeAttributes{Types: map[string]DataType{"nom": DataTypeString, "date": DataTypeString}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(back.Rows).To(Equal(data.Rows))
+
+		_, err = data.Filter(&RetypeAttributes{Types: map[string]DataType{"str": DataTypeNumeric}})
+		Expect(err).To(MatchError("value 'b' is not numeric"))
+	})
+
+	It("should compose while streaming", func() {
+		r, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		buf := new(bytes.Buffer)
+		Expect(ApplyFilters(buf, r,
+			&SelectAttributes{Names: []string{"play", "outlook", "temperature"}},
+			&RenameAttributes{Names: map[string]string{"temperature": "temp"}},
+			&RetypeAttributes{Types: map[string]DataType{"outlook": DataTypeString}},
+			&RemoveAttributes{Names: []string{"play"}},
+		)).To(Succeed())
+		Expect(buf.String()).To(HavePrefix("@RELATION weather\n\n@ATTRIBUTE outlook STRING\n@ATTRIBUTE temp NUMERIC\n\n@DATA\nsunny,85\n"))
+	})
+
+})
@@ -0,0 +1,106 @@
+package arff
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// SampleOptions configure Sample and SampleWeighted
+type SampleOptions struct {
+	// Seed initialises the random number generator, identical seeds
+	// produce identical samples
+	Seed int64
+}
+
+// Sample draws a uniform random sample of up to n rows, consuming it
+// exactly once and holding no more than n rows in memory. Sampled rows are
+// returned in stream order.
+func Sample(it Iterator, n int, opt *SampleOptions) ([]DataRow, error) {
+	if opt == nil {
+		opt = new(SampleOptions)
+	}
+	if n < 0 {
+		n = 0
+	}
+	rnd := rand.New(rand.NewSource(opt.Seed))
+
+	reservoir := make(sampledRows, 0, n)
+	for pos := 0; it.Next(); pos++ {
+		if len(reservoir) < n {
+			reservoir = append(reservoir, sampledRow{DataRow: *it.Row(), pos: pos})
+		} else if j := rnd.Intn(pos + 1); j < n {
+			reservoir[j] = sampledRow{DataRow: *it.Row(), pos: pos}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return reservoir.Rows(), nil
+}
+
+// SampleWeighted draws a random sample of up to n rows without
+// replacement, where the probability of a row being selected is
+// proportional to its weight. Like Sample, it consumes it exactly once,
+// holds no more than n rows in memory and returns rows in stream order.
+func SampleWeighted(it Iterator, n int, opt *SampleOptions) ([]DataRow, error) {
+	if opt == nil {
+		opt = new(SampleOptions)
+	}
+	if n < 0 {
+		n = 0
+	}
+	rnd := rand.New(rand.NewSource(opt.Seed))
+
+	// Efraimidis & Spirakis: keep the n rows with the largest u^(1/w)
+	reservoir := make(sampledRows, 0, n)
+	for pos := 0; it.Next(); pos++ {
+		row := it.Row()
+		key := math.Pow(rnd.Float64(), 1/row.weight())
+
+		if len(reservoir) < n {
+			heap.Push(&reservoir, sampledRow{DataRow: *row, pos: pos, key: key})
+		} else if n > 0 && key > reservoir[0].key {
+			reservoir[0] = sampledRow{DataRow: *row, pos: pos, key: key}
+			heap.Fix(&reservoir, 0)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return reservoir.Rows(), nil
+}
+
+// --------------------------------------------------------------------
+
+type sampledRow struct {
+	DataRow
+	pos int
+	key float64
+}
+
+// sampledRows is a min-heap of rows by key
+type sampledRows []sampledRow
+
+func (s sampledRows) Len() int            { return len(s) }
+func (s sampledRows) Less(i, j int) bool  { return s[i].key < s[j].key }
+func (s sampledRows) Swap(i, j int)       { s[i], s[j] = s[j], s[i] }
+func (s *sampledRows) Push(x interface{}) { *s = append(*s, x.(sampledRow)) }
+func (s *sampledRows) Pop() interface{} {
+	old := *s
+	x := old[len(old)-1]
+	*s = old[:len(old)-1]
+	return x
+}
+
+// Rows returns the rows in stream order
+func (s sampledRows) Rows() []DataRow {
+	sort.Slice(s, func(i, j int) bool { return s[i].pos < s[j].pos })
+
+	rows := make([]DataRow, len(s))
+	for i, r := range s {
+		rows[i] = r.DataRow
+	}
+	return rows
+}
@@ -0,0 +1,76 @@
+package arff
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sample", func() {
+
+	It("should sample uniformly", func() {
+		r, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		rows, err := Sample(r, 5, &SampleOptions{Seed: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows).To(HaveLen(5))
+
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(Sample(data.Iterator(), 5, &SampleOptions{Seed: 1})).To(Equal(rows))
+		Expect(Sample(data.Iterator(), 20, nil)).To(Equal(data.Rows))
+		Expect(Sample(data.Iterator(), 0, nil)).To(BeEmpty())
+	})
+
+	It("should be unbiased", func() {
+		data := &Dataset{Rows: make([]DataRow, 10)}
+		for i := range data.Rows {
+			data.Rows[i].Values = []interface{}{float64(i)}
+		}
+
+		counts := make([]int, 10)
+		for seed := int64(0); seed < 2000; seed++ {
+			rows, err := Sample(data.Iterator(), 2, &SampleOptions{Seed: seed})
+			Expect(err).NotTo(HaveOccurred())
+			for _, row := range rows {
+				counts[int(row.Values[0].(float64))]++
+			}
+		}
+		for _, n := range counts {
+			Expect(n).To(BeNumerically("~", 400, 80))
+		}
+	})
+
+})
+
+var _ = Describe("SampleWeighted", func() {
+
+	It("should sample proportionally to weight", func() {
+		data := &Dataset{Rows: []DataRow{
+			{Values: []interface{}{"a"}, Weight: 8},
+			{Values: []interface{}{"b"}},
+			{Values: []interface{}{"c"}},
+		}}
+
+		counts := make(map[interface{}]int)
+		for seed := int64(0); seed < 1000; seed++ {
+			rows, err := SampleWeighted(data.Iterator(), 1, &SampleOptions{Seed: seed})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(HaveLen(1))
+			counts[rows[0].Values[0]]++
+		}
+		Expect(counts["a"]).To(BeNumerically("~", 800, 60))
+		Expect(counts["b"]).To(BeNumerically("~", 100, 40))
+	})
+
+	It("should keep stream order", func() {
+		data, err := OpenDataset("testdata/iris.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		rows, err := SampleWeighted(data.Iterator(), 20, &SampleOptions{Seed: 3})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows).To(Equal(data.Rows))
+	})
+
+})
@@ -0,0 +1,174 @@
+package arff
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+)
+
+// ShuffleOptions configure Shuffle
+type ShuffleOptions struct {
+	// Seed initialises the random number generator, identical seeds
+	// produce identical permutations
+	Seed int64
+
+	// MaxRows is the maximum number of rows held in memory.
+	// Default: 1,000,000
+	MaxRows int
+
+	// Buckets is the number of temporary files rows are distributed
+	// into when they don't fit in memory. Default: 16
+	Buckets int
+
+	// TempDir is the directory for temporary files.
+	// Default: os.TempDir()
+	TempDir string
+}
+
+func (o *ShuffleOptions) norm() *ShuffleOptions {
+	var oo ShuffleOptions
+	if o != nil {
+		oo = *o
+	}
+	if oo.MaxRows < 1 {
+		oo.MaxRows = 1000000
+	}
+	if oo.Buckets < 2 {
+		oo.Buckets = 16
+	}
+	return &oo
+}
+
+// Shuffle writes all remaining rows of src to dst in random order.
+// Inputs which exceed MaxRows are distributed randomly into temporary ARFF
+// buckets on disk, which are shuffled individually and concatenated.
+func Shuffle(dst *Writer, src *Reader, opt *ShuffleOptions) error {
+	opt = opt.norm()
+	s := &shuffler{
+		ShuffleOptions: opt,
+		rel:            &src.Relation,
+		rnd:            rand.New(rand.NewSource(opt.Seed)),
+	}
+	return s.Shuffle(dst, src)
+}
+
+type shuffler struct {
+	*ShuffleOptions
+	rel *Relation
+	rnd *rand.Rand
+}
+
+func (s *shuffler) Shuffle(dst *Writer, src Iterator) error {
+	rows := make([]DataRow, 0, 1024)
+	for len(rows) < s.MaxRows && src.Next() {
+		rows = append(rows, *src.Row())
+	}
+	if err := src.Err(); err != nil {
+		return err
+	}
+
+	// shuffle in memory when the input is small enough
+	if len(rows) < s.MaxRows || !src.Next() {
+		if err := src.Err(); err != nil {
+			return err
+		}
+		s.rnd.Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+		for i := range rows {
+			if err := dst.Append(&rows[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// otherwise, distribute rows into buckets
+	buckets := make([]*tempFile, s.Buckets)
+	defer func() {
+		for _, b := range buckets {
+			if b != nil {
+				_ = b.Remove()
+			}
+		}
+	}()
+
+	for i := range buckets {
+		b, err := createTempFile(s.TempDir, "arff-shuffle-", s.rel)
+		if err != nil {
+			return err
+		}
+		buckets[i] = b
+	}
+	for i := range rows {
+		if err := buckets[s.rnd.Intn(len(buckets))].Append(&rows[i]); err != nil {
+			return err
+		}
+	}
+	rows = nil
+
+	for ok := true; ok; ok = src.Next() {
+		if err := buckets[s.rnd.Intn(len(buckets))].Append(src.Row()); err != nil {
+			return err
+		}
+	}
+	if err := src.Err(); err != nil {
+		return err
+	}
+
+	for i, b := range buckets {
+		if err := s.shuffleBucket(dst, b); err != nil {
+			return err
+		}
+		buckets[i] = nil
+	}
+	return nil
+}
+
+func (s *shuffler) shuffleBucket(dst *Writer, b *tempFile) error {
+	defer b.Remove()
+
+	r, err := b.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return s.Shuffle(dst, r)
+}
+
+// --------------------------------------------------------------------
+
+// tempFile is a temporary ARFF file
+type tempFile struct {
+	*Writer
+	name string
+}
+
+func createTempFile(dir, prefix string, rel *Relation) (*tempFile, error) {
+	file, err := ioutil.TempFile(dir, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := NewWriterSize(file, rel, DefaultBufferSize)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, err
+	}
+	w.own = file
+	return &tempFile{Writer: w, name: file.Name()}, nil
+}
+
+// Open closes the writer and opens the file for reading
+func (f *tempFile) Open() (*Reader, error) {
+	if err := f.Writer.Close(); err != nil {
+		return nil, err
+	}
+	return Open(f.name)
+}
+
+// Remove closes the writer and removes the file
+func (f *tempFile) Remove() error {
+	_ = f.Writer.Abort()
+	return os.Remove(f.name)
+}
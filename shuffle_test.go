@@ -0,0 +1,66 @@
+package arff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Shuffle", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "arff-test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	shuffle := func(fname string, opt *ShuffleOptions) *Dataset {
+		src, err := Open(fname)
+		Expect(err).NotTo(HaveOccurred())
+		defer src.Close()
+
+		buf := new(bytes.Buffer)
+		dst, err := NewWriter(buf, &src.Relation)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(Shuffle(dst, src, opt)).To(Succeed())
+		Expect(dst.Close()).To(Succeed())
+
+		r, err := NewReader(buf)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := ReadDataset(r)
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	It("should shuffle in memory", func() {
+		orig, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		data := shuffle("testdata/weather.arff", &ShuffleOptions{Seed: 1})
+		Expect(data.Relation).To(Equal(orig.Relation))
+		Expect(data.Rows).To(ConsistOf(orig.Rows))
+		Expect(data.Rows).NotTo(Equal(orig.Rows))
+		Expect(shuffle("testdata/weather.arff", &ShuffleOptions{Seed: 1})).To(Equal(data))
+	})
+
+	It("should shuffle out of core", func() {
+		orig, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		opt := &ShuffleOptions{Seed: 2, MaxRows: 3, Buckets: 3, TempDir: dir}
+		data := shuffle("testdata/weather.arff", opt)
+		Expect(data.Rows).To(ConsistOf(orig.Rows))
+		Expect(data.Rows).NotTo(Equal(orig.Rows))
+		Expect(shuffle("testdata/weather.arff", opt)).To(Equal(data))
+		Expect(ioutil.ReadDir(dir)).To(BeEmpty())
+	})
+
+})
@@ -0,0 +1,297 @@
+package arff
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortKey identifies an attribute to sort by
+type SortKey struct {
+	// Name is the attribute name
+	Name string
+
+	// Descending reverses the order of present values
+	Descending bool
+
+	// ByLabel orders nominal values by label instead of
+	// by their declaration order
+	ByLabel bool
+
+	// MissingLast places missing values after present ones,
+	// regardless of direction
+	MissingLast bool
+}
+
+// SortOptions configure Sort
+type SortOptions struct {
+	// MaxRows is the maximum number of rows held in memory.
+	// Default: 1,000,000
+	MaxRows int
+
+	// TempDir is the directory for temporary files.
+	// Default: os.TempDir()
+	TempDir string
+}
+
+func (o *SortOptions) norm() *SortOptions {
+	var oo SortOptions
+	if o != nil {
+		oo = *o
+	}
+	if oo.MaxRows < 1 {
+		oo.MaxRows = 1000000
+	}
+	return &oo
+}
+
+// Sort writes all remaining rows of src to dst, ordered by keys. The sort
+// is stable, rows with equal keys retain their input order. Inputs which
+// exceed MaxRows are sorted in chunks which are spilled to temporary files
+// and merged.
+func Sort(dst *Writer, src *Reader, keys []SortKey, opt *SortOptions) error {
+	opt = opt.norm()
+
+	cmp, err := newRowComparator(&src.Relation, keys)
+	if err != nil {
+		return err
+	}
+
+	var runs []*tempFile
+	defer func() {
+		for _, run := range runs {
+			_ = run.Remove()
+		}
+	}()
+
+	rows := make([]DataRow, 0, 1024)
+	for {
+		rows = rows[:0]
+		for len(rows) < opt.MaxRows && src.Next() {
+			rows = append(rows, *src.Row())
+		}
+		if err := src.Err(); err != nil {
+			return err
+		}
+		sort.SliceStable(rows, func(i, j int) bool { return cmp.Compare(&rows[i], &rows[j]) < 0 })
+
+		// write directly if all rows fit into memory
+		if runs == nil && len(rows) < opt.MaxRows {
+			for i := range rows {
+				if err := dst.Append(&rows[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if len(rows) != 0 {
+			run, err := createTempFile(opt.TempDir, "arff-sort-", &src.Relation)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+
+			for i := range rows {
+				if err := run.Append(&rows[i]); err != nil {
+					return err
+				}
+			}
+		}
+		if len(rows) < opt.MaxRows {
+			break
+		}
+	}
+
+	return mergeRuns(dst, runs, cmp)
+}
+
+// mergeRuns merges sorted runs into dst
+func mergeRuns(dst *Writer, runs []*tempFile, cmp *rowComparator) error {
+	queue := &mergeQueue{cmp: cmp}
+	for i, run := range runs {
+		r, err := run.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		if r.Next() {
+			queue.items = append(queue.items, mergeItem{Reader: r, run: i})
+		} else if err := r.Err(); err != nil {
+			return err
+		}
+	}
+	heap.Init(queue)
+
+	for queue.Len() != 0 {
+		item := queue.items[0]
+		if err := dst.Append(item.Row()); err != nil {
+			return err
+		}
+
+		if item.Next() {
+			heap.Fix(queue, 0)
+		} else if err := item.Err(); err != nil {
+			return err
+		} else {
+			heap.Pop(queue)
+		}
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------
+
+type rowComparator struct {
+	keys    []SortKey
+	index   []int
+	nominal []map[string]int
+}
+
+func newRowComparator(rel *Relation, keys []SortKey) (*rowComparator, error) {
+	c := &rowComparator{
+		keys:    keys,
+		index:   make([]int, len(keys)),
+		nominal: make([]map[string]int, len(keys)),
+	}
+
+	for i, key := range keys {
+		pos := rel.AttributeIndex(key.Name)
+		if pos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), key.Name)
+		}
+
+		attr := rel.Attributes[pos]
+		c.index[i] = pos
+
+		if attr.DataType == DataTypeNominal && !key.ByLabel {
+			c.nominal[i] = make(map[string]int, len(attr.NominalValues))
+			for n, v := range attr.NominalValues {
+				c.nominal[i][v] = n
+			}
+		}
+	}
+	return c, nil
+}
+
+// Compare returns -1, 0 or 1 if a is less, equal or greater than b
+func (c *rowComparator) Compare(a, b *DataRow) int {
+	for i, key := range c.keys {
+		pos := c.index[i]
+		va, vb := a.Values[pos], b.Values[pos]
+
+		// missing values
+		if va == nil || vb == nil {
+			if va == nil && vb == nil {
+				continue
+			}
+			n := 1
+			if va == nil {
+				n = -1
+			}
+			if key.MissingLast {
+				n = -n
+			}
+			return n
+		}
+
+		n := c.compareValues(i, va, vb)
+		if key.Descending {
+			n = -n
+		}
+		if n != 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+func (c *rowComparator) compareValues(i int, a, b interface{}) int {
+	if lookup := c.nominal[i]; lookup != nil {
+		sa, sb := fmt.Sprint(a), fmt.Sprint(b)
+		na, oka := lookup[sa]
+		nb, okb := lookup[sb]
+		switch {
+		case oka && okb:
+			return compareInts(na, nb)
+		case oka:
+			return -1
+		case okb:
+			return 1
+		}
+		return strings.Compare(sa, sb)
+	}
+	return compareValues(a, b)
+}
+
+// compareValues compares two present values, numerically, chronologically
+// or by their string representation
+func compareValues(a, b interface{}) int {
+	if fa, ok := numericValue(a); ok {
+		if fb, ok := numericValue(b); ok {
+			return compareFloats(fa, fb)
+		}
+	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return compareTimes(ta, tb)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareFloats(a, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareTimes(a, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	} else if a.After(b) {
+		return 1
+	}
+	return 0
+}
+
+type mergeItem struct {
+	*Reader
+	run int
+}
+
+// mergeQueue is a min-heap of run readers by their current row
+type mergeQueue struct {
+	items []mergeItem
+	cmp   *rowComparator
+}
+
+func (q *mergeQueue) Len() int      { return len(q.items) }
+func (q *mergeQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *mergeQueue) Less(i, j int) bool {
+	if n := q.cmp.Compare(q.items[i].Row(), q.items[j].Row()); n != 0 {
+		return n < 0
+	}
+	return q.items[i].run < q.items[j].run
+}
+func (q *mergeQueue) Push(x interface{}) { q.items = append(q.items, x.(mergeItem)) }
+func (q *mergeQueue) Pop() interface{} {
+	x := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return x
+}
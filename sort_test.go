@@ -0,0 +1,109 @@
+package arff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sort", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "arff-test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	sorted := func(fname string, keys []SortKey, opt *SortOptions) [][]interface{} {
+		src, err := Open(fname)
+		Expect(err).NotTo(HaveOccurred())
+		defer src.Close()
+
+		buf := new(bytes.Buffer)
+		dst, err := NewWriter(buf, &src.Relation)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(Sort(dst, src, keys, opt)).To(Succeed())
+		Expect(dst.Close()).To(Succeed())
+
+		r, err := NewReader(buf)
+		Expect(err).NotTo(HaveOccurred())
+		rows, err := r.ReadAll()
+		Expect(err).NotTo(HaveOccurred())
+
+		var vals [][]interface{}
+		for _, row := range rows {
+			vals = append(vals, row.Values)
+		}
+		return vals
+	}
+
+	It("should sort by multiple keys", func() {
+		vals := sorted("testdata/weather.arff", []SortKey{
+			{Name: "outlook"},
+			{Name: "temperature", Descending: true},
+		}, nil)
+		Expect(vals).To(HaveLen(14))
+		Expect(vals[:6]).To(Equal([][]interface{}{
+			{"sunny", 85.0, 85.0, "FALSE", "no"},
+			{"sunny", 80.0, 90.0, "TRUE", "no"},
+			{"sunny", 75.0, 70.0, "TRUE", "yes"},
+			{"sunny", 72.0, 95.0, "FALSE", "no"},
+			{"sunny", 69.0, 70.0, "FALSE", "yes"},
+			{"overcast", 83.0, 86.0, "FALSE", "yes"},
+		}))
+	})
+
+	It("should sort nominals by label", func() {
+		vals := sorted("testdata/weather.arff", []SortKey{
+			{Name: "outlook", ByLabel: true},
+		}, nil)
+		Expect(vals[0][0]).To(Equal("overcast"))
+		Expect(vals[4][0]).To(Equal("rainy"))
+		Expect(vals[9][0]).To(Equal("sunny"))
+	})
+
+	It("should place missing values", func() {
+		vals := sorted("testdata/iris.arff", []SortKey{{Name: "sepalWidth"}}, nil)
+		Expect(vals[0][1]).To(BeNil())
+		Expect(vals[1][1]).To(Equal(2.9))
+
+		vals = sorted("testdata/iris.arff", []SortKey{{Name: "sepalWidth", Descending: true, MissingLast: true}}, nil)
+		Expect(vals[0][1]).To(Equal(3.9))
+		Expect(vals[9][1]).To(BeNil())
+	})
+
+	It("should sort out of core", func() {
+		keys := []SortKey{{Name: "play"}, {Name: "humidity"}}
+		expected := sorted("testdata/weather.arff", keys, nil)
+		Expect(sorted("testdata/weather.arff", keys, &SortOptions{MaxRows: 3, TempDir: dir})).To(Equal(expected))
+		Expect(sorted("testdata/weather.arff", keys, &SortOptions{MaxRows: 7, TempDir: dir})).To(Equal(expected))
+		Expect(ioutil.ReadDir(dir)).To(BeEmpty())
+	})
+
+	It("should be stable", func() {
+		vals := sorted("testdata/weather.arff", []SortKey{{Name: "play"}}, &SortOptions{MaxRows: 4, TempDir: dir})
+		Expect(vals[:3]).To(Equal([][]interface{}{
+			{"overcast", 83.0, 86.0, "FALSE", "yes"},
+			{"rainy", 70.0, 96.0, "FALSE", "yes"},
+			{"rainy", 68.0, 80.0, "FALSE", "yes"},
+		}))
+	})
+
+	It("should reject unknown attributes", func() {
+		src, err := Open("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer src.Close()
+
+		err = Sort(nil, src, []SortKey{{Name: "unknown"}}, nil)
+		Expect(err).To(MatchError("unknown attribute 'unknown'"))
+	})
+
+})
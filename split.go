@@ -0,0 +1,184 @@
+package arff
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// SplitOptions configure Split and KFold
+type SplitOptions struct {
+	// Class is the name of the attribute partitions are stratified by, to
+	// preserve the class distribution of the dataset.
+	// Default: the class attribute of the dataset relation
+	Class string
+
+	// Unstratified disables stratification
+	Unstratified bool
+
+	// Seed initialises the random number generator, identical seeds
+	// produce identical partitions
+	Seed int64
+}
+
+// Fold is a pair of training and test partitions
+type Fold struct {
+	Train *Dataset
+	Test  *Dataset
+}
+
+// Split partitions data into a training and a test set, holding out
+// testRatio of the total row weight for testing. Rows retain their
+// original order and are shared with data. To split the rows of a Reader,
+// load them with ReadDataset first.
+func Split(data *Dataset, testRatio float64, opt *SplitOptions) (*Fold, error) {
+	if testRatio < 0 || testRatio > 1 {
+		return nil, fmt.Errorf("invalid test ratio %v", testRatio)
+	}
+
+	groups, err := shuffledGroups(data, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// carry the rounding remainder across groups so that ties do not all
+	// round towards the test set
+	assign := make([]int, len(data.Rows))
+	total, test := 0.0, 0.0
+	for _, group := range groups {
+		for _, i := range group {
+			total += data.Rows[i].weight()
+		}
+
+		target := total * testRatio
+		for _, i := range group {
+			w := data.Rows[i].weight()
+			if test+w/2 <= target {
+				test += w
+				assign[i] = 1
+			}
+		}
+	}
+
+	fold := &Fold{
+		Train: &Dataset{Relation: data.Relation},
+		Test:  &Dataset{Relation: data.Relation},
+	}
+	for i, row := range data.Rows {
+		fold.add(row, assign[i] == 1)
+	}
+	return fold, nil
+}
+
+// KFold partitions data into k folds of roughly equal row weight, as
+// Weka's StratifiedRemoveFolds does. Each fold's test set is the k-th
+// partition and its training set all remaining rows.
+func KFold(data *Dataset, k int, opt *SplitOptions) ([]Fold, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("invalid number of folds %d", k)
+	}
+
+	groups, err := shuffledGroups(data, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// deal rows into the lightest fold, continuing where the previous
+	// group left off so that folds stay balanced overall
+	assign := make([]int, len(data.Rows))
+	next := 0
+	for _, group := range groups {
+		weights := make([]float64, k)
+		for _, i := range group {
+			min := next
+			for j := 1; j < k; j++ {
+				if n := (next + j) % k; weights[n] < weights[min] {
+					min = n
+				}
+			}
+			weights[min] += data.Rows[i].weight()
+			assign[i] = min
+			next = (min + 1) % k
+		}
+	}
+
+	folds := make([]Fold, k)
+	for n := range folds {
+		folds[n] = Fold{
+			Train: &Dataset{Relation: data.Relation},
+			Test:  &Dataset{Relation: data.Relation},
+		}
+	}
+	for i, row := range data.Rows {
+		for n := range folds {
+			folds[n].add(row, assign[i] == n)
+		}
+	}
+	return folds, nil
+}
+
+func (f *Fold) add(row DataRow, test bool) {
+	if test {
+		f.Test.Rows = append(f.Test.Rows, row)
+	} else {
+		f.Train.Rows = append(f.Train.Rows, row)
+	}
+}
+
+// --------------------------------------------------------------------
+
+// shuffledGroups returns row indices grouped by class in order of first
+// appearance, shuffled within each group
+func shuffledGroups(data *Dataset, opt *SplitOptions) ([][]int, error) {
+	if opt == nil {
+		opt = new(SplitOptions)
+	}
+
+	class := -1
+	if !opt.Unstratified {
+		var err error
+		if class, err = classIndex(&data.Relation, opt.Class); err != nil {
+			return nil, err
+		}
+	}
+
+	var groups [][]int
+	index := make(map[interface{}]int)
+	for i, row := range data.Rows {
+		var key interface{}
+		if class > -1 {
+			if class >= len(row.Values) {
+				return nil, errAttrMismatch
+			}
+			key = row.Values[class]
+		}
+
+		n, ok := index[key]
+		if !ok {
+			n = len(groups)
+			index[key] = n
+			groups = append(groups, nil)
+		}
+		groups[n] = append(groups[n], i)
+	}
+
+	rnd := rand.New(rand.NewSource(opt.Seed))
+	for _, group := range groups {
+		rnd.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+	}
+	return groups, nil
+}
+
+// classIndex returns the index of the named attribute, defaulting to the
+// class attribute of rel
+func classIndex(rel *Relation, name string) (int, error) {
+	if name == "" {
+		name = rel.Class
+	}
+	if name == "" {
+		return rel.ClassIndex(), nil
+	}
+	if class := rel.AttributeIndex(name); class > -1 {
+		return class, nil
+	}
+	return -1, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+}
@@ -0,0 +1,117 @@
+package arff
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Split", func() {
+	var data *Dataset
+
+	BeforeEach(func() {
+		var err error
+		data, err = OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	classCounts := func(d *Dataset) map[interface{}]int {
+		counts := make(map[interface{}]int)
+		for _, row := range d.Rows {
+			counts[row.Values[4]]++
+		}
+		return counts
+	}
+
+	It("should split", func() {
+		fold, err := Split(data, 0.3, &SplitOptions{Seed: 1, Unstratified: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fold.Train.Relation).To(Equal(data.Relation))
+		Expect(fold.Train.Rows).To(HaveLen(10))
+		Expect(fold.Test.Rows).To(HaveLen(4))
+
+		again, err := Split(data, 0.3, &SplitOptions{Seed: 1, Unstratified: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(fold))
+	})
+
+	It("should split stratified", func() {
+		fold, err := Split(data, 0.5, &SplitOptions{Class: "play", Seed: 3})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fold.Test.Rows).To(HaveLen(7))
+		Expect(fold.Train.Rows).To(HaveLen(7))
+		Expect(classCounts(fold.Test)).To(Equal(map[interface{}]int{"yes": 4, "no": 3}))
+		Expect(classCounts(fold.Train)).To(Equal(map[interface{}]int{"yes": 5, "no": 2}))
+	})
+
+	It("should stratify by the relation class", func() {
+		fold, err := Split(data, 0.5, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fold.Test.Rows).To(HaveLen(7))
+		Expect(classCounts(fold.Test)).To(Equal(map[interface{}]int{"yes": 4, "no": 3}))
+
+		data.Class = "windy"
+		fold, err = Split(data, 0.5, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		windy := make(map[interface{}]int)
+		for _, row := range fold.Test.Rows {
+			windy[row.Values[3]]++
+		}
+		Expect(fold.Test.Rows).To(HaveLen(7))
+		Expect(windy).To(Equal(map[interface{}]int{"TRUE": 3, "FALSE": 4}))
+	})
+
+	It("should respect weights", func() {
+		data.Rows[0].Weight = 10
+		fold, err := Split(data, 0.5, &SplitOptions{Unstratified: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		var total float64
+		for _, row := range fold.Test.Rows {
+			total += row.weight()
+		}
+		Expect(total).To(BeNumerically("~", 11.5, 2))
+	})
+
+	It("should validate options", func() {
+		_, err := Split(data, 1.5, nil)
+		Expect(err).To(MatchError("invalid test ratio 1.5"))
+
+		_, err = Split(data, 0.5, &SplitOptions{Class: "missing"})
+		Expect(err).To(MatchError("unknown attribute 'missing'"))
+	})
+
+})
+
+var _ = Describe("KFold", func() {
+
+	It("should create stratified folds", func() {
+		data, err := OpenDataset("testdata/weather.arff")
+		Expect(err).NotTo(HaveOccurred())
+
+		folds, err := KFold(data, 3, &SplitOptions{Class: "play", Seed: 7})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(folds).To(HaveLen(3))
+
+		var sizes []int
+		var yes []int
+		for _, fold := range folds {
+			Expect(len(fold.Train.Rows) + len(fold.Test.Rows)).To(Equal(14))
+			sizes = append(sizes, len(fold.Test.Rows))
+
+			n := 0
+			for _, row := range fold.Test.Rows {
+				if row.Values[4] == "yes" {
+					n++
+				}
+			}
+			yes = append(yes, n)
+		}
+		Expect(sizes).To(ConsistOf(5, 5, 4))
+		Expect(yes).To(ConsistOf(3, 3, 3))
+
+		_, err = KFold(data, 1, nil)
+		Expect(err).To(MatchError("invalid number of folds 1"))
+	})
+
+})
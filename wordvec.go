@@ -0,0 +1,249 @@
+package arff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TermWeighting defines how term occurrences are weighted
+type TermWeighting uint8
+
+const (
+	// TermFrequency counts term occurrences
+	TermFrequency TermWeighting = iota
+	// TermPresence indicates term occurrence by 1 and absence by 0
+	TermPresence
+	// TermTFIDF scales term frequencies by their inverse document
+	// frequencies: tf * log(N / df)
+	TermTFIDF
+)
+
+// StringToWordVector is a Filter which replaces string attributes by
+// numeric attributes, one per term of a vocabulary, which is built by
+// Fit. Term attributes are appended in lexical order. Each row is a
+// document, missing values contain no terms. Term vectors are mostly
+// zero, see Writer.SetSparse for a compact output format.
+type StringToWordVector struct {
+	// Attributes are the names of the attributes to tokenise, which must
+	// not include the class attribute.
+	// Default: all string attributes except the class attribute
+	Attributes []string
+
+	// Tokenizer splits strings into terms.
+	// Default: split on characters which are neither letters nor digits
+	Tokenizer func(string) []string
+
+	// Lowercase converts terms to lower case
+	Lowercase bool
+
+	// Stopwords are terms to ignore
+	Stopwords []string
+
+	// MinDocFreq is the minimum number of documents a term must occur in
+	// to be included in the vocabulary. Default: 1
+	MinDocFreq int
+
+	// Weighting is the term weighting. Default: TermFrequency
+	Weighting TermWeighting
+
+	// Prefix is prepended to the names of term attributes
+	Prefix string
+
+	// Vocabulary maps terms to their document frequencies. It is built
+	// by Fit or may be supplied.
+	Vocabulary map[string]int
+
+	// Documents is the number of documents seen by Fit
+	Documents int
+
+	index     []int // positions of tokenised attributes
+	keep      []int // positions of retained attributes
+	terms     map[string]int
+	idf       []float64
+	stopwords map[string]bool
+}
+
+// Fit builds the vocabulary from all remaining rows of it, replacing an
+// existing one
+func (f *StringToWordVector) Fit(rel *Relation, it Iterator) error {
+	index, err := f.attributes(rel)
+	if err != nil {
+		return err
+	}
+	f.buildStopwords()
+
+	docFreq := make(map[string]int)
+	docs := 0
+	for it.Next() {
+		seen := make(map[string]bool)
+		f.tokenize(it.Row(), index, func(term string) {
+			if !seen[term] {
+				seen[term] = true
+				docFreq[term]++
+			}
+		})
+		docs++
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	min := f.MinDocFreq
+	if min < 1 {
+		min = 1
+	}
+	for term, n := range docFreq {
+		if n < min {
+			delete(docFreq, term)
+		}
+	}
+
+	f.Vocabulary, f.Documents = docFreq, docs
+	return nil
+}
+
+// Init implements Filter
+func (f *StringToWordVector) Init(in *Relation) (*Relation, error) {
+	index, err := f.attributes(in)
+	if err != nil {
+		return nil, err
+	}
+	f.buildStopwords()
+
+	tokenised := make(map[int]bool, len(index))
+	for _, pos := range index {
+		tokenised[pos] = true
+	}
+
+	out := &Relation{Name: in.Name, Comments: in.Comments, Class: in.className()}
+	f.index, f.keep = index, f.keep[:0]
+	for pos, attr := range in.Attributes {
+		if !tokenised[pos] {
+			out.Attributes = append(out.Attributes, attr)
+			f.keep = append(f.keep, pos)
+		}
+	}
+
+	terms := make([]string, 0, len(f.Vocabulary))
+	for term := range f.Vocabulary {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	f.terms, f.idf = make(map[string]int, len(terms)), f.idf[:0]
+	for _, term := range terms {
+		name := f.Prefix + term
+		if err := out.AddAttribute(name, DataTypeNumeric, nil); err != nil {
+			return nil, fmt.Errorf("%s '%s'", err.Error(), name)
+		}
+		f.terms[term] = len(f.idf)
+
+		idf := 0.0
+		if df := f.Vocabulary[term]; df > 0 && f.Documents > 0 {
+			idf = math.Log(float64(f.Documents) / float64(df))
+		}
+		f.idf = append(f.idf, idf)
+	}
+	return out, nil
+}
+
+// Apply implements Filter
+func (f *StringToWordVector) Apply(row *DataRow) (*DataRow, error) {
+	values := make([]interface{}, len(f.keep)+len(f.terms))
+	for i, pos := range f.keep {
+		values[i] = row.Values[pos]
+	}
+
+	counts := make([]float64, len(f.terms))
+	f.tokenize(row, f.index, func(term string) {
+		if n, ok := f.terms[term]; ok {
+			counts[n]++
+		}
+	})
+
+	offset := len(f.keep)
+	for n, c := range counts {
+		switch f.Weighting {
+		case TermPresence:
+			if c > 0 {
+				c = 1
+			}
+		case TermTFIDF:
+			c *= f.idf[n]
+		}
+		values[offset+n] = c
+	}
+	return &DataRow{Values: values, Weight: row.Weight}, nil
+}
+
+func (f *StringToWordVector) tokenize(row *DataRow, index []int, fn func(string)) {
+	tokenizer := f.Tokenizer
+	if tokenizer == nil {
+		tokenizer = tokenizeWords
+	}
+
+	for _, pos := range index {
+		v := row.Values[pos]
+		if v == nil {
+			continue
+		}
+
+		for _, term := range tokenizer(fmt.Sprint(v)) {
+			if f.Lowercase {
+				term = strings.ToLower(term)
+			}
+			if term != "" && !f.stopwords[term] {
+				fn(term)
+			}
+		}
+	}
+}
+
+func (f *StringToWordVector) buildStopwords() {
+	f.stopwords = make(map[string]bool, len(f.Stopwords))
+	for _, w := range f.Stopwords {
+		if f.Lowercase {
+			w = strings.ToLower(w)
+		}
+		f.stopwords[w] = true
+	}
+}
+
+func (f *StringToWordVector) attributes(rel *Relation) ([]int, error) {
+	var index []int
+	classPos := rel.ClassIndex()
+	if len(f.Attributes) == 0 {
+		for pos, attr := range rel.Attributes {
+			if attr.DataType == DataTypeString && pos != classPos {
+				index = append(index, pos)
+			}
+		}
+		return index, nil
+	}
+
+	for _, name := range f.Attributes {
+		pos := rel.AttributeIndex(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("%s '%s'", errUnknownAttr.Error(), name)
+		}
+		if rel.Attributes[pos].DataType != DataTypeString {
+			return nil, fmt.Errorf("%s '%s'", errNonStringAttr.Error(), name)
+		}
+		if pos == classPos {
+			return nil, fmt.Errorf("%s '%s'", errClassAttr.Error(), name)
+		}
+		index = append(index, pos)
+	}
+	sort.Ints(index)
+	return index, nil
+}
+
+// tokenizeWords splits s on characters which are neither letters nor digits
+func tokenizeWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,115 @@
+package arff
+
+import (
+	"bytes"
+	"math"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StringToWordVector", func() {
+	var data *Dataset
+
+	BeforeEach(func() {
+		data = &Dataset{Relation: Relation{Name: "docs", Attributes: []Attribute{
+			{Name: "id", DataType: DataTypeNumeric},
+			{Name: "text", DataType: DataTypeString},
+			{Name: "class", DataType: DataTypeNominal, NominalValues: []string{"a", "b"}},
+		}}, Rows: []DataRow{
+			{Values: []interface{}{1.0, "The cat sat on the mat.", "a"}},
+			{Values: []interface{}{2.0, "A dog, a cat!", "b"}, Weight: 2},
+			{Values: []interface{}{3.0, nil, "a"}},
+		}}
+	})
+
+	It("should count terms", func() {
+		f := &StringToWordVector{Lowercase: true, Stopwords: []string{"the", "A", "on"}}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Documents).To(Equal(3))
+		Expect(f.Vocabulary).To(Equal(map[string]int{"cat": 2, "sat": 1, "mat": 1, "dog": 1}))
+
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		for _, attr := range out.Attributes {
+			names = append(names, attr.Name)
+		}
+		Expect(names).To(Equal([]string{"id", "class", "cat", "dog", "mat", "sat"}))
+		Expect(out.Class).To(Equal("class"))
+		Expect(out.Attributes[2].DataType).To(Equal(DataTypeNumeric))
+		Expect(out.Rows).To(Equal([]DataRow{
+			{Values: []interface{}{1.0, "a", 1.0, 0.0, 1.0, 1.0}},
+			{Values: []interface{}{2.0, "b", 1.0, 1.0, 0.0, 0.0}, Weight: 2},
+			{Values: []interface{}{3.0, "a", 0.0, 0.0, 0.0, 0.0}},
+		}))
+	})
+
+	It("should prune rare terms", func() {
+		f := &StringToWordVector{MinDocFreq: 2, Prefix: "w_"}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Vocabulary).To(Equal(map[string]int{"cat": 2}))
+
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Attributes[2].Name).To(Equal("w_cat"))
+	})
+
+	It("should weight terms", func() {
+		f := &StringToWordVector{Tokenizer: strings.Fields, Weighting: TermPresence}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		Expect(f.Vocabulary).To(HaveKey("cat!"))
+		Expect(f.Vocabulary).To(HaveKeyWithValue("a", 1))
+
+		out, err := data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows[1].Values[2:]).To(ContainElement(1.0))
+		Expect(out.Rows[1].Values[2:]).NotTo(ContainElement(2.0))
+
+		f = &StringToWordVector{Lowercase: true, Weighting: TermTFIDF}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+		out, err = data.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+
+		// "a" occurs twice in document 2, "the" twice in document 1
+		pos := out.AttributeIndex("a")
+		Expect(out.Rows[1].Values[pos]).To(BeNumerically("~", 2*math.Log(3), 1e-9))
+		pos = out.AttributeIndex("cat")
+		Expect(out.Rows[1].Values[pos]).To(BeNumerically("~", math.Log(1.5), 1e-9))
+	})
+
+	It("should validate", func() {
+		Expect((&StringToWordVector{Attributes: []string{"x"}}).Fit(&data.Relation, data.Iterator())).To(MatchError("unknown attribute 'x'"))
+		Expect((&StringToWordVector{Attributes: []string{"id"}}).Fit(&data.Relation, data.Iterator())).To(MatchError("non-string attribute 'id'"))
+
+		data.Class = "text"
+		Expect((&StringToWordVector{Attributes: []string{"text"}}).Fit(&data.Relation, data.Iterator())).To(MatchError("cannot transform class attribute 'text'"))
+		data.Class = ""
+
+		_, err := data.Filter(&StringToWordVector{Vocabulary: map[string]int{"id": 1}})
+		Expect(err).To(MatchError("redefined attribute 'id'"))
+	})
+
+	It("should write sparse output", func() {
+		f := &StringToWordVector{Lowercase: true}
+		Expect(f.Fit(&data.Relation, data.Iterator())).To(Succeed())
+
+		rel, it, err := FilterRows(&data.Relation, data.Iterator(), f)
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		w, err := NewWriter(buf, rel)
+		Expect(err).NotTo(HaveOccurred())
+		w.SetSparse(true)
+		Expect(w.AppendAll(it)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(buf.String()).To(HaveSuffix("@DATA\n{0 1,3 1,5 1,6 1,7 1,8 2}\n{0 2,1 b,2 2,3 1,4 1},{2}\n{0 3}\n"))
+
+		out, err := readDataset(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Rows[2].Values[:2]).To(Equal([]interface{}{3.0, "a"}))
+	})
+
+})
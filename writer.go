@@ -4,18 +4,26 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// DefaultBufferSize is the default size of the Writer's internal buffer
+const DefaultBufferSize = 64 * 1024
+
 // Writer instances can write ARFF data
 type Writer struct {
-	attrs int
-	buf   *writeBuffer
-	dst   io.Writer
-	own   io.Closer
+	attrs  []Attribute
+	size   int
+	sparse bool
+	buf    *writeBuffer
+	dst    io.Writer
+	own    io.Closer
+	err    error // first flush error
 }
 
 // Create creates a new relation file in fname and returns a writer
@@ -25,7 +33,7 @@ func Create(fname string, r *Relation) (*Writer, error) {
 		return nil, err
 	}
 
-	w, err := NewWriter(file, r)
+	w, err := NewWriterSize(file, r, DefaultBufferSize)
 	if err != nil {
 		_ = file.Close()
 		return nil, err
@@ -35,18 +43,92 @@ func Create(fname string, r *Relation) (*Writer, error) {
 	return w, nil
 }
 
-// NewWriter creates a new writer from a generic io.WriteCloser
+// CreateAtomic creates a new relation file in fname, similar to Create.
+// Data is written to a temporary file in the same directory which is
+// synced and renamed to fname on Close, so readers never observe a
+// partially written file. Call Abort to discard the temporary file.
+func CreateAtomic(fname string, r *Relation) (*Writer, error) {
+	file, err := CreateAtomicFile(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := NewWriterSize(file, r, DefaultBufferSize)
+	if err != nil {
+		_ = file.Abort()
+		return nil, err
+	}
+
+	w.own = file
+	return w, nil
+}
+
+// NewWriter creates a new writer from a generic io.Writer. Rows are
+// written through to dst immediately, use NewWriterSize to buffer them.
 func NewWriter(dst io.Writer, r *Relation) (*Writer, error) {
+	return NewWriterSize(dst, r, 0)
+}
+
+// NewWriterSize creates a new writer which buffers up to size bytes
+// before writing them to dst. Remaining data is only written on Flush or
+// Close, so either must be called. A size of zero writes every row through
+// immediately. Writers returned by Create and CreateAtomic are buffered
+// with DefaultBufferSize.
+func NewWriterSize(dst io.Writer, r *Relation, size int) (*Writer, error) {
+	return NewWriterOptions(dst, r, &WriterOptions{BufferSize: size})
+}
+
+// WriterOptions configure NewWriterOptions
+type WriterOptions struct {
+	// BufferSize is the size of the internal buffer, see NewWriterSize.
+	// Default: 0 (unbuffered)
+	BufferSize int
+
+	// ClassCommentPrefix marks the header comment which designates the
+	// class attribute. Default: "@class "
+	ClassCommentPrefix string
+
+	// NoClassComment disables writing the class comment
+	NoClassComment bool
+}
+
+func (o *WriterOptions) norm() *WriterOptions {
+	var oo WriterOptions
+	if o != nil {
+		oo = *o
+	}
+	if oo.BufferSize < 0 {
+		oo.BufferSize = 0
+	}
+	if oo.ClassCommentPrefix == "" {
+		oo.ClassCommentPrefix = classCommentPrefix
+	}
+	return &oo
+}
+
+// NewWriterOptions creates a new writer like NewWriter, with custom
+// options
+func NewWriterOptions(dst io.Writer, r *Relation, opt *WriterOptions) (*Writer, error) {
+	opt = opt.norm()
 	if err := r.validate(); err != nil {
 		return nil, err
 	}
 
 	w := &Writer{
-		attrs: len(r.Attributes),
+		attrs: r.Attributes,
+		size:  opt.BufferSize,
 		buf:   new(writeBuffer),
 		dst:   dst,
 	}
 
+	if err := w.buf.WriteComments(r.Comments); err != nil {
+		return nil, err
+	}
+	if r.Class != "" && !opt.NoClassComment {
+		if err := w.buf.WriteComments([]string{opt.ClassCommentPrefix + quote(r.Class)}); err != nil {
+			return nil, err
+		}
+	}
 	if err := w.buf.WriteRelation(r.Name); err != nil {
 		return nil, err
 	}
@@ -56,7 +138,7 @@ func NewWriter(dst io.Writer, r *Relation) (*Writer, error) {
 	if _, err := w.buf.WriteString("@DATA\n"); err != nil {
 		return nil, err
 	}
-	if err := w.buf.FlushTo(dst); err != nil {
+	if err := w.flushIfFull(); err != nil {
 		return nil, err
 	}
 	return w, nil
@@ -64,19 +146,103 @@ func NewWriter(dst io.Writer, r *Relation) (*Writer, error) {
 
 // Append appends a DataRow
 func (w *Writer) Append(row *DataRow) error {
-	if len(row.Values) != w.attrs {
+	if len(row.Values) != len(w.attrs) {
 		return errAttrMismatch
 	}
 
-	for i, v := range row.Values {
-		if i != 0 {
-			if err := w.buf.WriteByte(','); err != nil {
-				return err
-			}
+	// discard partially written rows on error
+	mark := w.buf.Len()
+	if err := w.writeRow(row); err != nil {
+		w.buf.Truncate(mark)
+		return err
+	}
+	return w.flushIfFull()
+}
+
+// SetSparse enables or disables the sparse data format for subsequently
+// appended rows. Sparse rows omit the values readers assume for missing
+// entries, i.e. zero numbers and dates, empty strings and the first
+// labels of nominal attributes, e.g. "{1 X,3 5}".
+func (w *Writer) SetSparse(sparse bool) {
+	w.sparse = sparse
+}
+
+// AppendAll appends all remaining rows of it
+func (w *Writer) AppendAll(it Iterator) error {
+	for it.Next() {
+		if err := w.Append(it.Row()); err != nil {
+			return err
 		}
-		if err := w.buf.WriteRowValue(v); err != nil {
+	}
+	return it.Err()
+}
+
+// Flush writes any buffered data to the underlying writer
+func (w *Writer) Flush() error {
+	err := w.buf.FlushTo(w.dst)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return err
+}
+
+// Close flushes buffered data and closes the underlying writer. It
+// returns the first flush error, if any. Files created with CreateAtomic
+// are discarded instead of renamed into place in that case.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if err == nil {
+		err = w.err
+	}
+	if file, ok := w.own.(*AtomicFile); ok && err != nil {
+		_ = file.Abort()
+		return err
+	}
+	if w.own != nil {
+		if e := w.own.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Abort discards buffered data and closes the underlying writer without
+// flushing. Files created with CreateAtomic are removed.
+func (w *Writer) Abort() error {
+	w.buf.Reset()
+
+	switch own := w.own.(type) {
+	case *AtomicFile:
+		return own.Abort()
+	case io.Closer:
+		return own.Close()
+	}
+	return nil
+}
+
+func (w *Writer) flushIfFull() error {
+	if w.buf.Len() < w.size {
+		return nil
+	}
+	return w.Flush()
+}
+
+func (w *Writer) writeRow(row *DataRow) error {
+	if w.sparse {
+		if err := w.writeSparseValues(row.Values); err != nil {
 			return err
 		}
+	} else {
+		for i, v := range row.Values {
+			if i != 0 {
+				if err := w.buf.WriteByte(','); err != nil {
+					return err
+				}
+			}
+			if err := w.buf.WriteRowValue(v); err != nil {
+				return err
+			}
+		}
 	}
 
 	if row.Weight != 0 {
@@ -93,18 +259,38 @@ func (w *Writer) Append(row *DataRow) error {
 			return err
 		}
 	}
-	if err := w.buf.WriteByte('\n'); err != nil {
+	return w.buf.WriteByte('\n')
+}
+
+func (w *Writer) writeSparseValues(values []interface{}) error {
+	if err := w.buf.WriteByte('{'); err != nil {
 		return err
 	}
-	return w.buf.FlushTo(w.dst)
-}
 
-// Close closes the underlying writer
-func (w *Writer) Close() error {
-	if w.own != nil {
-		return w.own.Close()
+	first := true
+	for i, v := range values {
+		if w.attrs[i].isZero(v) {
+			continue
+		}
+
+		if !first {
+			if err := w.buf.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		if err := w.buf.WriteInt(int64(i)); err != nil {
+			return err
+		}
+		if err := w.buf.WriteByte(' '); err != nil {
+			return err
+		}
+		if err := w.buf.WriteRowValue(v); err != nil {
+			return err
+		}
 	}
-	return nil
+	return w.buf.WriteByte('}')
 }
 
 // --------------------------------------------------------------------
@@ -114,8 +300,7 @@ type writeBuffer struct {
 }
 
 func (w *writeBuffer) WriteFloat(f float64) error {
-	str := strings.TrimRight(strconv.FormatFloat(f, 'f', -1, 64), "0")
-	_, err := w.WriteString(str)
+	_, err := w.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
 	return err
 }
 
@@ -149,6 +334,27 @@ func (w *writeBuffer) WriteRelation(name string) error {
 	return nil
 }
 
+func (w *writeBuffer) WriteComments(comments []string) error {
+	for _, c := range comments {
+		if err := w.WriteByte('%'); err != nil {
+			return err
+		}
+		if c != "" {
+			if err := w.WriteByte(' '); err != nil {
+				return err
+			}
+			// keep multi-line comments intact
+			if _, err := w.WriteString(strings.Replace(c, "\n", "\n% ", -1)); err != nil {
+				return err
+			}
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (w *writeBuffer) WriteAttributes(attrs []Attribute) error {
 	for _, attr := range attrs {
 		if err := w.WriteAttribute(&attr); err != nil {
@@ -159,7 +365,9 @@ func (w *writeBuffer) WriteAttributes(attrs []Attribute) error {
 }
 
 func (w *writeBuffer) WriteAttribute(attr *Attribute) (err error) {
-	if _, err = w.WriteString("@ATTRIBUTE"); err != nil {
+	if err = w.WriteComments(attr.Comments); err != nil {
+		return
+	} else if _, err = w.WriteString("@ATTRIBUTE"); err != nil {
 		return
 	} else if err = w.WriteByte(' '); err != nil {
 		return
@@ -255,3 +463,83 @@ func (w *writeBuffer) FlushTo(to io.Writer) error {
 	w.Reset()
 	return err
 }
+
+// --------------------------------------------------------------------
+
+// AtomicFile is a file which is written to a temporary location in the
+// same directory and only renamed into place on Close.
+type AtomicFile struct {
+	*os.File
+	path string
+}
+
+// CreateAtomicFile creates a new AtomicFile for fname. Files that replace
+// an existing fname keep its mode. Call Abort to discard the file.
+func CreateAtomicFile(fname string) (*AtomicFile, error) {
+	dir, base := filepath.Split(fname)
+	if dir == "" {
+		dir = "."
+	}
+
+	// keep the mode of replaced files, apply the umask to new ones
+	perm, replace := os.FileMode(0666), false
+	if info, err := os.Stat(fname); err == nil {
+		perm, replace = info.Mode().Perm(), true
+	}
+
+	file, err := openTempFile(dir, "."+base+".tmp", perm)
+	if err != nil {
+		return nil, err
+	}
+	if replace {
+		if err := file.Chmod(perm); err != nil {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+			return nil, err
+		}
+	}
+	return &AtomicFile{File: file, path: fname}, nil
+}
+
+// openTempFile is like ioutil.TempFile, but creates the file with perm
+// (before umask) instead of 0600.
+func openTempFile(dir, prefix string, perm os.FileMode) (*os.File, error) {
+	for i := 0; i < 10000; i++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 10))
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) {
+			continue
+		}
+		return file, err
+	}
+	return nil, &os.PathError{Op: "createtemp", Path: filepath.Join(dir, prefix+"*"), Err: os.ErrExist}
+}
+
+// Close syncs the temporary file and renames it to the target path.
+func (f *AtomicFile) Close() error {
+	if err := f.Sync(); err != nil {
+		_ = f.Abort()
+		return err
+	}
+	if err := f.File.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	// sync the parent directory to persist the rename, where supported
+	if dir, err := os.Open(filepath.Dir(f.path)); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+	return nil
+}
+
+// Abort closes and removes the temporary file.
+func (f *AtomicFile) Abort() error {
+	_ = f.File.Close()
+	return os.Remove(f.Name())
+}
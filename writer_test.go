@@ -2,7 +2,12 @@ package arff
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -40,4 +45,384 @@ var _ = Describe("Writer", func() {
 		Expect(dst.String()).To(BeIdenticalTo(string(bin)))
 	})
 
+	It("should write comments", func() {
+		dst := new(bytes.Buffer)
+		w, err := NewWriter(dst, &Relation{
+			Name:     "commented",
+			Comments: []string{"Title: commented", "", "multi\nline"},
+			Attributes: []Attribute{
+				{Name: "num", DataType: DataTypeNumeric, Comments: []string{"a number"}},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(Equal(`% Title: commented
+%
+% multi
+% line
+@RELATION commented
+
+% a number
+@ATTRIBUTE num NUMERIC
+
+@DATA
+`))
+	})
+
+	It("should write class comments", func() {
+		dst := new(bytes.Buffer)
+		w, err := NewWriter(dst, &Relation{
+			Name:       "classy",
+			Comments:   []string{"Title: classy"},
+			Attributes: []Attribute{{Name: "the num", DataType: DataTypeNumeric}, {Name: "str", DataType: DataTypeString}},
+			Class:      "the num",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(HavePrefix("% Title: classy\n% @class 'the num'\n@RELATION classy\n"))
+
+		r, err := NewReader(dst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation.Class).To(Equal("the num"))
+		Expect(r.Relation.Comments).To(Equal([]string{"Title: classy"}))
+
+		_, err = NewWriter(dst, &Relation{Name: "classy", Class: "missing"})
+		Expect(err).To(MatchError("unknown attribute 'missing'"))
+	})
+
+	It("should write custom class comments", func() {
+		rel := &Relation{
+			Name:       "classy",
+			Attributes: []Attribute{{Name: "num", DataType: DataTypeNumeric}, {Name: "str", DataType: DataTypeString}},
+			Class:      "num",
+		}
+
+		dst := new(bytes.Buffer)
+		w, err := NewWriterOptions(dst, rel, &WriterOptions{ClassCommentPrefix: "class: "})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(HavePrefix("% class: num\n@RELATION classy\n"))
+
+		r, err := NewReaderOptions(dst, &ReaderOptions{ClassCommentPrefix: "class: "})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation.Class).To(Equal("num"))
+
+		dst.Reset()
+		w, err = NewWriterOptions(dst, rel, &WriterOptions{NoClassComment: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(HavePrefix("@RELATION classy\n"))
+	})
+
+	It("should preserve comments on round-trip", func() {
+		src, err := Open("testdata/iris.arff")
+		Expect(err).NotTo(HaveOccurred())
+		defer src.Close()
+
+		dst := new(bytes.Buffer)
+		w, err := NewWriter(dst, &src.Relation)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		r, err := NewReader(dst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation).To(Equal(src.Relation))
+
+		src, err = NewReader(strings.NewReader("% lead\n@relation x % named\n@attribute y real % inline\n% trailing\n@data\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		dst.Reset()
+		w, err = NewWriter(dst, &src.Relation)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		r, err = NewReader(dst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Relation).To(Equal(src.Relation))
+		Expect(r.Relation.Comments).To(Equal([]string{"lead", "named", "trailing"}))
+		Expect(r.Attributes[0].Comments).To(Equal([]string{"inline"}))
+	})
+
+	It("should write round floats", func() {
+		dst := new(bytes.Buffer)
+		w, err := NewWriter(dst, &Relation{
+			Name:       "floats",
+			Attributes: []Attribute{{Name: "num", DataType: DataTypeNumeric}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Append(&DataRow{Values: []interface{}{10.0}, Weight: 20})).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(HaveSuffix("@DATA\n10,{20}\n"))
+	})
+
+	It("should buffer rows until flushed", func() {
+		dst := new(bytes.Buffer)
+		w, err := NewWriterSize(dst, &Relation{
+			Name:       "buffered",
+			Attributes: []Attribute{{Name: "num", DataType: DataTypeNumeric}},
+		}, DefaultBufferSize)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Append(&DataRow{Values: []interface{}{1}})).To(Succeed())
+		Expect(dst.Len()).To(Equal(0))
+
+		Expect(w.Flush()).To(Succeed())
+		Expect(dst.String()).To(Equal("@RELATION buffered\n\n@ATTRIBUTE num NUMERIC\n\n@DATA\n1\n"))
+
+		Expect(w.Append(&DataRow{Values: []interface{}{2}})).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(HaveSuffix("@DATA\n1\n2\n"))
+	})
+
+	It("should write through unbuffered", func() {
+		dst := new(bytes.Buffer)
+		w, err := NewWriter(dst, &Relation{
+			Name:       "unbuffered",
+			Attributes: []Attribute{{Name: "num", DataType: DataTypeNumeric}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dst.String()).To(HaveSuffix("@DATA\n"))
+
+		Expect(w.Append(&DataRow{Values: []interface{}{1}})).To(Succeed())
+		Expect(dst.String()).To(HaveSuffix("@DATA\n1\n"))
+	})
+
+	It("should discard partial rows on errors", func() {
+		dst := new(bytes.Buffer)
+		w, err := NewWriter(dst, &Relation{
+			Name: "partial",
+			Attributes: []Attribute{
+				{Name: "num", DataType: DataTypeNumeric},
+				{Name: "str", DataType: DataTypeString},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Append(&DataRow{Values: []interface{}{1, struct{}{}}})).To(MatchError("invalid value {} (struct {})"))
+		Expect(w.Append(&DataRow{Values: []interface{}{2, "x"}})).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(HaveSuffix("@DATA\n2,x\n"))
+	})
+
+	It("should write sparse rows", func() {
+		dst := new(bytes.Buffer)
+		w, err := NewWriter(dst, &Relation{
+			Name: "sparse",
+			Attributes: []Attribute{
+				{Name: "num", DataType: DataTypeNumeric},
+				{Name: "str", DataType: DataTypeString},
+				{Name: "nom", DataType: DataTypeNominal, NominalValues: []string{"a", "b c"}},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Append(&DataRow{Values: []interface{}{1, "x", "a"}})).To(Succeed())
+
+		w.SetSparse(true)
+		Expect(w.Append(&DataRow{Values: []interface{}{0, "x y", "b c"}})).To(Succeed())
+		Expect(w.Append(&DataRow{Values: []interface{}{0.0, "", nil}, Weight: 2})).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(HaveSuffix("@DATA\n1,x,a\n{1 'x y',2 'b c'}\n{2 ?},{2}\n"))
+
+		r, err := NewReader(dst)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.ReadAll()).To(Equal([]DataRow{
+			{Values: []interface{}{1.0, "x", "a"}},
+			{Values: []interface{}{0.0, "x y", "b c"}},
+			{Values: []interface{}{0.0, "", nil}, Weight: 2},
+		}))
+	})
+
+	It("should round-trip sparse nominal and date values", func() {
+		epoch := time.Unix(0, 0).UTC()
+		day := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		dst := new(bytes.Buffer)
+		w, err := NewWriter(dst, &Relation{
+			Name: "sparse",
+			Attributes: []Attribute{
+				{Name: "nom", DataType: DataTypeNominal, NominalValues: []string{"a", "b"}},
+				{Name: "str", DataType: DataTypeString},
+				{Name: "day", DataType: DataTypeDate},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		w.SetSparse(true)
+		Expect(w.Append(&DataRow{Values: []interface{}{"a", "", epoch}})).To(Succeed())
+		Expect(w.Append(&DataRow{Values: []interface{}{"b", "a", day}})).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(HaveSuffix("@DATA\n{}\n{0 b,1 a,2 2018-06-01T00:00:00}\n"))
+
+		r, err := NewReader(dst)
+		Expect(err).NotTo(HaveOccurred())
+		rows, err := r.ReadAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows).To(HaveLen(2))
+		Expect(rows[0].Values[:2]).To(Equal([]interface{}{"a", ""}))
+		Expect(rows[0].Values[2].(time.Time).Equal(epoch)).To(BeTrue())
+		Expect(rows[1].Values[:2]).To(Equal([]interface{}{"b", "a"}))
+		Expect(rows[1].Values[2].(time.Time).Equal(day)).To(BeTrue())
+	})
+
+	Describe("CreateAtomic", func() {
+		var dir string
+		var rel *Relation
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "arff-test")
+			Expect(err).NotTo(HaveOccurred())
+
+			rel = &Relation{
+				Name:       "atomic",
+				Attributes: []Attribute{{Name: "num", DataType: DataTypeNumeric}},
+			}
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should rename into place on close", func() {
+			fname := filepath.Join(dir, "atomic.arff")
+			w, err := CreateAtomic(fname, rel)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Append(&DataRow{Values: []interface{}{1}})).To(Succeed())
+			Expect(w.Flush()).To(Succeed())
+			Expect(fname).NotTo(BeAnExistingFile())
+
+			Expect(w.Close()).To(Succeed())
+			Expect(ioutil.ReadFile(fname)).To(HaveSuffix("@DATA\n1\n"))
+			Expect(ioutil.ReadDir(dir)).To(HaveLen(1))
+		})
+
+		It("should create files with default permissions", func() {
+			ref, err := os.OpenFile(filepath.Join(dir, "ref"), os.O_CREATE|os.O_WRONLY, 0666)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ref.Close()).To(Succeed())
+			refInfo, err := os.Stat(ref.Name())
+			Expect(err).NotTo(HaveOccurred())
+
+			fname := filepath.Join(dir, "atomic.arff")
+			w, err := CreateAtomic(fname, rel)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+
+			info, err := os.Stat(fname)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(refInfo.Mode()))
+		})
+
+		It("should keep the mode of replaced files", func() {
+			fname := filepath.Join(dir, "atomic.arff")
+			Expect(ioutil.WriteFile(fname, []byte("secret"), 0600)).To(Succeed())
+			Expect(os.Chmod(fname, 0640)).To(Succeed())
+
+			w, err := CreateAtomic(fname, rel)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+
+			info, err := os.Stat(fname)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0640)))
+		})
+
+		It("should remove partial files on abort", func() {
+			fname := filepath.Join(dir, "atomic.arff")
+			w, err := CreateAtomic(fname, rel)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Append(&DataRow{Values: []interface{}{1}})).To(Succeed())
+			Expect(w.Flush()).To(Succeed())
+
+			Expect(w.Abort()).To(Succeed())
+			Expect(fname).NotTo(BeAnExistingFile())
+			Expect(ioutil.ReadDir(dir)).To(BeEmpty())
+		})
+
+		It("should not replace files on failed flushes", func() {
+			fname := filepath.Join(dir, "atomic.arff")
+			Expect(ioutil.WriteFile(fname, []byte("original"), 0644)).To(Succeed())
+
+			w, err := CreateAtomic(fname, rel)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Append(&DataRow{Values: []interface{}{1}})).To(Succeed())
+
+			w.dst = failingWriter{}
+			Expect(w.Close()).To(MatchError("no space left on device"))
+			Expect(ioutil.ReadFile(fname)).To(Equal([]byte("original")))
+			Expect(ioutil.ReadDir(dir)).To(HaveLen(1))
+		})
+
+		It("should not replace files after earlier failed flushes", func() {
+			fname := filepath.Join(dir, "atomic.arff")
+			Expect(ioutil.WriteFile(fname, []byte("original"), 0644)).To(Succeed())
+
+			w, err := CreateAtomic(fname, rel)
+			Expect(err).NotTo(HaveOccurred())
+
+			dst := w.dst
+			w.dst = failingWriter{}
+			Expect(w.Append(&DataRow{Values: []interface{}{1}})).To(Succeed())
+			Expect(w.Flush()).To(MatchError("no space left on device"))
+
+			w.dst = dst
+			Expect(w.Append(&DataRow{Values: []interface{}{2}})).To(Succeed())
+			Expect(w.Close()).To(MatchError("no space left on device"))
+			Expect(ioutil.ReadFile(fname)).To(Equal([]byte("original")))
+			Expect(ioutil.ReadDir(dir)).To(HaveLen(1))
+		})
+	})
+
 })
+
+// failingWriter fails all writes, as a full disk would
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("no space left on device")
+}
+
+func BenchmarkWriter_Append(b *testing.B) {
+	b.Run("unbuffered", func(b *testing.B) {
+		benchmarkWriterAppend(b, 0)
+	})
+	b.Run("buffered", func(b *testing.B) {
+		benchmarkWriterAppend(b, DefaultBufferSize)
+	})
+}
+
+func benchmarkWriterAppend(b *testing.B, size int) {
+	dir, err := ioutil.TempDir("", "arff-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file, err := os.Create(filepath.Join(dir, "bench.arff"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer file.Close()
+
+	w, err := NewWriterSize(file, &Relation{
+		Name: "bench",
+		Attributes: []Attribute{
+			{Name: "num", DataType: DataTypeNumeric},
+			{Name: "str", DataType: DataTypeString},
+			{Name: "nom", DataType: DataTypeNominal, NominalValues: []string{"a", "b"}},
+		},
+	}, size)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	row := &DataRow{Values: []interface{}{1.5, "some text", "a"}}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := w.Append(row); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		b.Fatal(err)
+	}
+}